@@ -0,0 +1,169 @@
+// Authenticated bearer protection combining 128-EEA3 ciphering and 128-EIA3 integrity behind crypto/cipher.AEAD.
+// The order of protection follows the layer using it: PDCP (TS 36.323 / TS 38.323) computes MAC-I over the
+// header and data and then ciphers the data together with MAC-I, NAS (TS 24.301 / TS 24.501) ciphers first
+// and then computes the MAC over the ciphered message.
+
+package aead
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"github.com/frankurcrazy/zuc"
+	"github.com/frankurcrazy/zuc/eea3"
+	"github.com/frankurcrazy/zuc/eia3"
+)
+
+type Order int
+
+const (
+	// MACThenEncrypt computes MAC-I over additional data and plaintext, then ciphers plaintext and MAC-I (PDCP).
+	MACThenEncrypt = Order(iota)
+	// EncryptThenMAC ciphers the plaintext, then computes MAC-I over additional data and ciphertext (NAS).
+	EncryptThenMAC
+)
+
+const (
+	KeySize   = 16
+	NonceSize = 5
+	MACSize   = 4
+
+	maxMessageSize = eia3.MaxLength / 8
+)
+
+var (
+	ErrKeySize        = errors.New("aead: keys must be 16 bytes")
+	ErrOrder          = errors.New("aead: unknown protection order")
+	ErrAuthentication = errors.New("aead: message authentication failed")
+	ErrMessageTooLong = errors.New("aead: message exceeds maximum size")
+)
+
+type bearerAEAD struct {
+	ck    []byte
+	ik    []byte
+	order Order
+}
+
+// Nonce encodes COUNT, BEARER and DIRECTION as COUNT || BEARER || DIRECTION || 00.
+func Nonce(count uint32, bearer uint32, direction zuc.KeyDirection) []byte {
+	nonce := make([]byte, NonceSize)
+	binary.BigEndian.PutUint32(nonce[:4], count)
+	nonce[4] = uint8((bearer << 3) | ((uint32(direction)&1)<<2)&0xfc)
+
+	return nonce
+}
+
+func parseNonce(nonce []byte) (uint32, uint32, zuc.KeyDirection) {
+	return binary.BigEndian.Uint32(nonce[:4]), uint32(nonce[4] >> 3), zuc.KeyDirection((nonce[4] >> 2) & 1)
+}
+
+func NewAEAD(ck []byte, ik []byte, order Order) (cipher.AEAD, error) {
+	if len(ck) != KeySize || len(ik) != KeySize {
+		return nil, ErrKeySize
+	}
+
+	if order != MACThenEncrypt && order != EncryptThenMAC {
+		return nil, ErrOrder
+	}
+
+	a := &bearerAEAD{
+		ck:    append([]byte{}, ck...),
+		ik:    append([]byte{}, ik...),
+		order: order,
+	}
+
+	return a, nil
+}
+
+func (a *bearerAEAD) NonceSize() int {
+	return NonceSize
+}
+
+func (a *bearerAEAD) Overhead() int {
+	return MACSize
+}
+
+func (a *bearerAEAD) encrypt(nonce []byte, m []byte) []byte {
+	count, bearer, direction := parseNonce(nonce)
+
+	return eea3.NewEEA3(a.ck, count, bearer, direction).Encrypt(m, uint32(len(m))*8)
+}
+
+func (a *bearerAEAD) mac(nonce []byte, additionalData []byte, m []byte) []byte {
+	count, bearer, direction := parseNonce(nonce)
+
+	input := make([]byte, 0, len(additionalData)+len(m))
+	input = append(input, additionalData...)
+	input = append(input, m...)
+
+	return eia3.NewEIA3(a.ik, count, bearer, direction).Hash(input, uint32(len(input))*8)
+}
+
+func (a *bearerAEAD) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	if len(nonce) != NonceSize {
+		panic("aead: incorrect nonce length given to Seal")
+	}
+
+	if uint64(len(plaintext))+uint64(len(additionalData))+MACSize > maxMessageSize {
+		panic("aead: message too large")
+	}
+
+	var out []byte
+	switch a.order {
+	case MACThenEncrypt:
+		mac := a.mac(nonce, additionalData, plaintext)
+		input := make([]byte, 0, len(plaintext)+MACSize)
+		input = append(input, plaintext...)
+		input = append(input, mac...)
+		out = a.encrypt(nonce, input)
+	case EncryptThenMAC:
+		out = a.encrypt(nonce, plaintext)
+		out = append(out, a.mac(nonce, additionalData, out)...)
+	}
+
+	return append(dst, out...)
+}
+
+func (a *bearerAEAD) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != NonceSize {
+		panic("aead: incorrect nonce length given to Open")
+	}
+
+	if len(ciphertext) < MACSize {
+		return nil, ErrAuthentication
+	}
+
+	if uint64(len(ciphertext))+uint64(len(additionalData)) > maxMessageSize {
+		return nil, ErrMessageTooLong
+	}
+
+	var plaintext []byte
+	switch a.order {
+	case MACThenEncrypt:
+		// MAC-I is ciphered along with the data, so it can only be checked once deciphered.
+		// The deciphered data is withheld and wiped if the check fails.
+		decrypted := a.encrypt(nonce, ciphertext)
+		plaintext = decrypted[:len(decrypted)-MACSize]
+
+		mac := a.mac(nonce, additionalData, plaintext)
+		if subtle.ConstantTimeCompare(mac, decrypted[len(plaintext):]) != 1 {
+			for i := range decrypted {
+				decrypted[i] = 0
+			}
+
+			return nil, ErrAuthentication
+		}
+	case EncryptThenMAC:
+		body := ciphertext[:len(ciphertext)-MACSize]
+
+		mac := a.mac(nonce, additionalData, body)
+		if subtle.ConstantTimeCompare(mac, ciphertext[len(body):]) != 1 {
+			return nil, ErrAuthentication
+		}
+
+		plaintext = a.encrypt(nonce, body)
+	}
+
+	return append(dst, plaintext...), nil
+}
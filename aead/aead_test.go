@@ -0,0 +1,95 @@
+package aead
+
+import (
+	"encoding/hex"
+	"github.com/frankurcrazy/zuc"
+	"github.com/frankurcrazy/zuc/eea3"
+	"github.com/frankurcrazy/zuc/eia3"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestNonce(t *testing.T) {
+	nonce := Nonce(0x66035492, 0x0f, zuc.KEY_DOWNLINK)
+	expected, _ := hex.DecodeString("660354927c")
+
+	assert.Equal(t, expected, nonce)
+
+	count, bearer, direction := parseNonce(nonce)
+	assert.Equal(t, uint32(0x66035492), count)
+	assert.Equal(t, uint32(0x0f), bearer)
+	assert.Equal(t, zuc.KEY_DOWNLINK, direction)
+}
+
+func TestNewAEAD(t *testing.T) {
+	_, err := NewAEAD(make([]byte, 15), make([]byte, 16), MACThenEncrypt)
+	assert.Equal(t, ErrKeySize, err)
+
+	_, err = NewAEAD(make([]byte, 16), make([]byte, 16), Order(7))
+	assert.Equal(t, ErrOrder, err)
+}
+
+func TestAEAD(t *testing.T) {
+	ck, _ := hex.DecodeString("173d14ba5003731d7a60049470f00a29")
+	ik, _ := hex.DecodeString("c9e6cec4607c72db000aefa88385ab0a")
+	header, _ := hex.DecodeString("8001")
+	plaintext, _ := hex.DecodeString("6cf65340735552ab0c9752fa6f9025fe0bd675d9005875b2")
+	nonce := Nonce(0x66035492, 0x0f, zuc.KEY_UPLINK)
+
+	expected := map[Order][]byte{}
+
+	mac := eia3.NewEIA3(ik, 0x66035492, 0x0f, zuc.KEY_UPLINK).Hash(append(append([]byte{}, header...), plaintext...), uint32(len(header)+len(plaintext))*8)
+	expected[MACThenEncrypt] = eea3.NewEEA3(ck, 0x66035492, 0x0f, zuc.KEY_UPLINK).Encrypt(append(append([]byte{}, plaintext...), mac...), uint32(len(plaintext)+4)*8)
+
+	ciphertext := eea3.NewEEA3(ck, 0x66035492, 0x0f, zuc.KEY_UPLINK).Encrypt(plaintext, uint32(len(plaintext))*8)
+	mac = eia3.NewEIA3(ik, 0x66035492, 0x0f, zuc.KEY_UPLINK).Hash(append(append([]byte{}, header...), ciphertext...), uint32(len(header)+len(ciphertext))*8)
+	expected[EncryptThenMAC] = append(ciphertext, mac...)
+
+	for order, name := range map[Order]string{MACThenEncrypt: "MAC then encrypt", EncryptThenMAC: "Encrypt then MAC"} {
+		t.Run(name, func(t *testing.T) {
+			a, err := NewAEAD(ck, ik, order)
+			assert.Nil(t, err)
+			assert.Equal(t, NonceSize, a.NonceSize())
+			assert.Equal(t, MACSize, a.Overhead())
+
+			sealed := a.Seal([]byte{0xaa}, nonce, plaintext, header)
+			assert.Equal(t, append([]byte{0xaa}, expected[order]...), sealed, "Sealed PDU mismatched!")
+
+			opened, err := a.Open(nil, nonce, sealed[1:], header)
+			assert.Nil(t, err)
+			assert.Equal(t, plaintext, opened, "Plaintext mismatched!")
+
+			for i := range sealed[1:] {
+				tampered := append([]byte{}, sealed[1:]...)
+				tampered[i] ^= 0x01
+
+				_, err = a.Open(nil, nonce, tampered, header)
+				assert.Equal(t, ErrAuthentication, err, "Tampered byte %d should fail.", i)
+			}
+
+			_, err = a.Open(nil, nonce, sealed[1:], []byte{0x80, 0x02})
+			assert.Equal(t, ErrAuthentication, err, "Tampered additional data should fail.")
+
+			_, err = a.Open(nil, Nonce(0x66035493, 0x0f, zuc.KEY_UPLINK), sealed[1:], header)
+			assert.Equal(t, ErrAuthentication, err, "Wrong COUNT should fail.")
+
+			_, err = a.Open(nil, nonce, sealed[1:4], header)
+			assert.Equal(t, ErrAuthentication, err, "Truncated PDU should fail.")
+
+			empty := a.Seal(nil, nonce, nil, header)
+			assert.Equal(t, MACSize, len(empty))
+
+			opened, err = a.Open(nil, nonce, empty, header)
+			assert.Nil(t, err)
+			assert.Equal(t, 0, len(opened))
+
+			assert.Panics(t, func() { a.Seal(nil, nonce[:4], plaintext, header) })
+
+			large := make([]byte, maxMessageSize-len(header)-MACSize+1)
+			assert.Panics(t, func() { a.Seal(nil, nonce, large, header) })
+
+			_, err = a.Open(nil, nonce, append(large, make([]byte, MACSize)...), header)
+			assert.Equal(t, ErrMessageTooLong, err)
+		})
+	}
+}
@@ -0,0 +1,186 @@
+// Registry of 3GPP ciphering and integrity algorithms keyed by their 4-bit identifiers,
+// TS 33.401 clause 5.1.3 and TS 33.501 clause 5.11.1. The null algorithms EEA0/NEA0 and
+// EIA0/NIA0 are built in; packages implementing other algorithms register themselves when
+// imported, e.g. eea3 and eia3 register identifier 3.
+//
+// The constructors of those packages have the shape of eea3.NewEEA3: they return the algorithm
+// itself and report no error, panicking on a key of the wrong size. NewCipher and NewIntegrity
+// check the key size and return the package's ErrKeySize instead.
+
+package algorithm
+
+import (
+	"errors"
+	"github.com/frankurcrazy/zuc"
+	"sync"
+)
+
+// Algorithm identifiers. NEA/NIA share the values of their EPS counterparts.
+const (
+	EEA0 = uint8(0)
+	EEA1 = uint8(1)
+	EEA2 = uint8(2)
+	EEA3 = uint8(3)
+
+	EIA0 = uint8(0)
+	EIA1 = uint8(1)
+	EIA2 = uint8(2)
+	EIA3 = uint8(3)
+
+	NEA0 = EEA0
+	NEA1 = EEA1
+	NEA2 = EEA2
+	NEA3 = EEA3
+
+	NIA0 = EIA0
+	NIA1 = EIA1
+	NIA2 = EIA2
+	NIA3 = EIA3
+
+	MaxID   = 15
+	MACSize = 4
+)
+
+var (
+	ErrID         = errors.New("algorithm: identifier must fit in 4 bits")
+	ErrUnknown    = errors.New("algorithm: no algorithm registered for identifier")
+	ErrRegistered = errors.New("algorithm: identifier already registered")
+)
+
+// Cipher ciphers the first blength bits of m, as eea3.EEA3 does.
+type Cipher interface {
+	Encrypt(m []byte, blength uint32) []byte
+}
+
+// Integrity computes the 32-bit MAC of the first blength bits of m, as eia3.EIA3 does.
+type Integrity interface {
+	Hash(m []byte, blength uint32) []byte
+}
+
+type CipherFactory func(key []byte, count uint32, bearer uint32, direction zuc.KeyDirection) (Cipher, error)
+type IntegrityFactory func(key []byte, count uint32, bearer uint32, direction zuc.KeyDirection) (Integrity, error)
+
+var (
+	mu          sync.RWMutex
+	ciphers     = map[uint8]CipherFactory{}
+	integrities = map[uint8]IntegrityFactory{}
+)
+
+type null struct{}
+
+// Encrypt of the null cipher returns a copy of the first blength bits of m. Like the other ciphers, the
+// output has the size of m with the bits past blength set to zero.
+func (null) Encrypt(m []byte, blength uint32) []byte {
+	output := make([]byte, len(m))
+	length := int((uint64(blength) + 7) / 8)
+	copy(output, m[:length])
+
+	if rem := blength % 8; rem > 0 {
+		output[length-1] &= uint8(0xff) << (8 - rem)
+	}
+
+	return output
+}
+
+// Hash of the null integrity algorithm is all zeros.
+func (null) Hash(m []byte, blength uint32) []byte {
+	return make([]byte, MACSize)
+}
+
+func init() {
+	ciphers[EEA0] = func([]byte, uint32, uint32, zuc.KeyDirection) (Cipher, error) {
+		return null{}, nil
+	}
+
+	integrities[EIA0] = func([]byte, uint32, uint32, zuc.KeyDirection) (Integrity, error) {
+		return null{}, nil
+	}
+}
+
+// RegisterCipher makes a ciphering algorithm available under id. It is meant to be called from init.
+func RegisterCipher(id uint8, f CipherFactory) error {
+	if id > MaxID {
+		return ErrID
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, ok := ciphers[id]; ok {
+		return ErrRegistered
+	}
+
+	ciphers[id] = f
+
+	return nil
+}
+
+// RegisterIntegrity makes an integrity algorithm available under id. It is meant to be called from init.
+func RegisterIntegrity(id uint8, f IntegrityFactory) error {
+	if id > MaxID {
+		return ErrID
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, ok := integrities[id]; ok {
+		return ErrRegistered
+	}
+
+	integrities[id] = f
+
+	return nil
+}
+
+func HasCipher(id uint8) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	_, ok := ciphers[id]
+
+	return ok
+}
+
+func HasIntegrity(id uint8) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	_, ok := integrities[id]
+
+	return ok
+}
+
+// NewCipher returns the ciphering algorithm id keyed for one COUNT, BEARER and DIRECTION.
+func NewCipher(id uint8, key []byte, count uint32, bearer uint32, direction zuc.KeyDirection) (Cipher, error) {
+	if id > MaxID {
+		return nil, ErrID
+	}
+
+	mu.RLock()
+	f, ok := ciphers[id]
+	mu.RUnlock()
+
+	if !ok {
+		return nil, ErrUnknown
+	}
+
+	return f(key, count, bearer, direction)
+}
+
+// NewIntegrity returns the integrity algorithm id keyed for one COUNT, BEARER and DIRECTION.
+func NewIntegrity(id uint8, key []byte, count uint32, bearer uint32, direction zuc.KeyDirection) (Integrity, error) {
+	if id > MaxID {
+		return nil, ErrID
+	}
+
+	mu.RLock()
+	f, ok := integrities[id]
+	mu.RUnlock()
+
+	if !ok {
+		return nil, ErrUnknown
+	}
+
+	return f(key, count, bearer, direction)
+}
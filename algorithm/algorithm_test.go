@@ -0,0 +1,61 @@
+package algorithm
+
+import (
+	"github.com/frankurcrazy/zuc"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type xor uint8
+
+func (x xor) Encrypt(m []byte, blength uint32) []byte {
+	out := make([]byte, len(m))
+	for i := range m {
+		out[i] = m[i] ^ uint8(x)
+	}
+
+	return out
+}
+
+func TestNull(t *testing.T) {
+	m := []byte{0x01, 0x02, 0x03}
+
+	cipher, err := NewCipher(EEA0, nil, 0, 0, zuc.KEY_UPLINK)
+	assert.Nil(t, err)
+	assert.Equal(t, m, cipher.Encrypt(m, 24))
+	assert.Equal(t, []byte{0x01, 0x02, 0x00}, cipher.Encrypt(m, 22))
+	assert.Equal(t, []byte{0x01, 0x00, 0x00}, cipher.Encrypt(m, 8))
+	assert.Equal(t, []byte{0x00, 0x00, 0x00}, cipher.Encrypt(m, 7))
+	assert.Equal(t, []byte{0xff, 0xe0, 0x00}, cipher.Encrypt([]byte{0xff, 0xff, 0xff}, 11))
+
+	integrity, err := NewIntegrity(NIA0, nil, 0, 0, zuc.KEY_UPLINK)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0, 0, 0, 0}, integrity.Hash(m, 24))
+}
+
+func TestRegister(t *testing.T) {
+	_, err := NewCipher(14, nil, 0, 0, zuc.KEY_UPLINK)
+	assert.Equal(t, ErrUnknown, err)
+	assert.False(t, HasCipher(14))
+
+	assert.Nil(t, RegisterCipher(14, func(key []byte, count uint32, bearer uint32, direction zuc.KeyDirection) (Cipher, error) {
+		return xor(key[0]), nil
+	}))
+	assert.True(t, HasCipher(14))
+
+	cipher, err := NewCipher(14, []byte{0xff}, 0, 0, zuc.KEY_UPLINK)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0xfe, 0xfd}, cipher.Encrypt([]byte{0x01, 0x02}, 16))
+
+	assert.Equal(t, ErrRegistered, RegisterCipher(EEA0, nil))
+	assert.Equal(t, ErrRegistered, RegisterIntegrity(EIA0, nil))
+	assert.Equal(t, ErrID, RegisterCipher(16, nil))
+	assert.Equal(t, ErrID, RegisterIntegrity(16, nil))
+
+	_, err = NewIntegrity(16, nil, 0, 0, zuc.KEY_UPLINK)
+	assert.Equal(t, ErrID, err)
+
+	_, err = NewIntegrity(13, nil, 0, 0, zuc.KEY_UPLINK)
+	assert.Equal(t, ErrUnknown, err)
+	assert.False(t, HasIntegrity(13))
+}
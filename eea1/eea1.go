@@ -0,0 +1,90 @@
+// 128-EEA1, the SNOW 3G based confidentiality algorithm of 3GPP TS 33.401 Annex B.1.2, which is
+// UEA2 of ETSI / SAGE specification of the 3GPP Confidentiality and Integrity Algorithms UEA2 & UIA2,
+// Document 1: UEA2 and UIA2 Specification. Version 1.1 from 6th September 2006.
+
+package eea1
+
+import (
+	"encoding/binary"
+	"errors"
+	"github.com/frankurcrazy/zuc"
+	"github.com/frankurcrazy/zuc/algorithm"
+	"github.com/frankurcrazy/zuc/snow3g"
+)
+
+var ErrKeySize = errors.New("eea1: key must be 16 bytes")
+
+type EEA1 struct {
+	snow *snow3g.SNOW3G
+}
+
+// makeKeyIV orders CK and the f8 IV words as k0..k3 and IV0..IV3 for the SNOW 3G initialization,
+// where k3 is the first word of CK and IV3 = COUNT, IV2 = BEARER || DIRECTION || 0...0.
+func makeKeyIV(ck []byte, count uint32, bearer uint32, direction zuc.KeyDirection) ([16]byte, [16]byte) {
+	k := [16]byte{}
+	for i := 0; i < 4; i += 1 {
+		copy(k[4*i:4*i+4], ck[12-4*i:16-4*i])
+	}
+
+	bd := (bearer&0x1f)<<27 | (uint32(direction)&1)<<26
+
+	iv := [16]byte{}
+	binary.BigEndian.PutUint32(iv[0:], bd)
+	binary.BigEndian.PutUint32(iv[4:], count)
+	binary.BigEndian.PutUint32(iv[8:], bd)
+	binary.BigEndian.PutUint32(iv[12:], count)
+
+	return k, iv
+}
+
+// NewEEA1 panics if ck is not 16 bytes.
+func NewEEA1(ck []byte, count uint32, bearer uint32, direction zuc.KeyDirection) *EEA1 {
+	if len(ck) != 16 {
+		panic(ErrKeySize)
+	}
+
+	k, iv := makeKeyIV(ck, count, bearer, direction)
+
+	return &EEA1{snow: snow3g.NewSNOW3G(k[:], iv[:])}
+}
+
+func (e *EEA1) Encrypt(m []byte, blength uint32) []byte {
+	if uint64(blength) > uint64(len(m))*8 {
+		panic("eea1: buffer is shorter than bit length")
+	}
+
+	zeroBits := blength & 0x7
+	length := int((blength + 7) >> 3)
+	output := make([]byte, len(m))
+
+	for i := 0; i < length; i += 4 {
+		k := e.snow.NextKey()
+		for j := 0; j < 4 && i+j < length; j += 1 {
+			output[i+j] = m[i+j] ^ uint8(k>>(24-8*uint(j)))
+		}
+	}
+
+	if zeroBits > 0 {
+		output[length-1] = output[length-1] & (uint8(0xff) << (8 - zeroBits))
+	}
+
+	return output
+}
+
+func (e *EEA1) Decrypt(m []byte, blength uint32) []byte {
+	return e.Encrypt(m, blength)
+}
+
+func init() {
+	err := algorithm.RegisterCipher(algorithm.EEA1, func(ck []byte, count uint32, bearer uint32, direction zuc.KeyDirection) (algorithm.Cipher, error) {
+		if len(ck) != 16 {
+			return nil, ErrKeySize
+		}
+
+		return NewEEA1(ck, count, bearer, direction), nil
+	})
+
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,119 @@
+package eea1
+
+import (
+	"encoding/hex"
+	"github.com/frankurcrazy/zuc"
+	"github.com/frankurcrazy/zuc/algorithm"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+type TestSet struct {
+	Key        string
+	Count      uint32
+	Bearer     uint32
+	Direction  zuc.KeyDirection
+	BitLength  uint32
+	Plaintext  string
+	Ciphertext string
+}
+
+// 128-EEA1 is UEA2 with a 5-bit BEARER, so the UEA2 test data of ETSI / SAGE Document 3:
+// Implementors' Test Data applies as is.
+var testSets = map[string]TestSet{
+	"UEA2 Test Set 1": TestSet{
+		Key:        "d3 c5 d5 92 32 7f b1 1c 40 35 c6 68 0a f8 c6 d1",
+		Count:      0x398a59b4,
+		Bearer:     0x15,
+		Direction:  zuc.KEY_DOWNLINK,
+		BitLength:  253,
+		Plaintext:  "981ba682 4c1bfb1a b4854720 29b71d80 8ce33e2c c3c0b5fc 1f3de8a6 dc66b1f0",
+		Ciphertext: "5d5bfe75 eb04f68c e0a12377 ea00b37d 47c6a0ba 06309155 086a859c 4341b378",
+	},
+	"UEA2 Test Set 2": TestSet{
+		Key:       "2b d6 45 9f 82 c5 b3 00 95 2c 49 10 48 81 ff 48",
+		Count:     0x72a4f20f,
+		Bearer:    0x0c,
+		Direction: zuc.KEY_DOWNLINK,
+		BitLength: 798,
+		Plaintext: `7ec61272 743bf161 4726446a 6c38ced1 66f6ca76 eb543004 4286346c ef130f92
+                        922b0345 0d3a9975 e5bd2ea0 eb55ad8e 1b199e3e c4316020 e9a1b285 e7627953
+                        59b7bdfd 39bef4b2 484583d5 afe082ae e638bf5f d5a60619 3901a08f 4ab41aab
+                        9b134880`,
+		Ciphertext: `8ceba629 43dced3a 0990b06e a1b0a2c4 fb3cedc7 1b369f42 ba64c1eb 6665e72a
+                         a1c9bb0d eaa20fe8 6058b8ba ee2c2e7f 0becce48 b52932a5 3c9d5f93 1a3a7c53
+                         2259af43 25e2a65e 3084ad5f 6a513b7b ddc1b65f 0aa0d97a 053db55a 88c4c4f9
+                         605e4140`,
+	},
+	"UEA2 Test Set 3": TestSet{
+		Key:       "0a 8b 6b d8 d9 b0 8b 08 d6 4e 32 d1 81 77 77 fb",
+		Count:     0x544d49cd,
+		Bearer:    0x04,
+		Direction: zuc.KEY_UPLINK,
+		BitLength: 310,
+		Plaintext: `fd40a41d 370a1f65 74509568 7d47ba1d 36d2349e 23f64439 2c8ea9c4 9d40c132
+                        71aff264 d0f248`,
+		Ciphertext: `48148e54 52a210c0 5f46bc80 dc6f7349 5b02048c 1b958b02 6102ca97 280279a4
+                         c18d2ee3 08921c`,
+	},
+	"UEA2 Test Set 4": TestSet{
+		Key:       "aa 1f 95 ae a5 33 bc b3 2e b6 3b f5 2d 8f 83 1a",
+		Count:     0x72d8c671,
+		Bearer:    0x10,
+		Direction: zuc.KEY_DOWNLINK,
+		BitLength: 1022,
+		Plaintext: `fb1b96c5 c8badfb2 e8e8edfd e78e57f2 ad81e741 03fc430a 534dcc37 afcec70e
+                        1517bb06 f27219da e49022dd c47a068d e4c9496a 951a6b09 edbdc864 c7adbd74
+                        0ac50c02 2f3082ba fd22d781 97c5d508 b977bca1 3f32e652 e74ba728 576077ce
+                        628c535e 87dc6077 ba07d290 68590c8c b5f1088e 082cfa0e c961302d 69cf3d44`,
+		Ciphertext: `ffcfc2fe ad6c094e 96c589d0 f6779b67 84246c3c 4d1cea20 3db3901f 40ad4fd7
+                         138bc6d7 7e8320cb 102f497f dd44a269 a96ecb28 617700e3 32eb2f73 6b34f4f2
+                         693094e2 2ff94f9b e4723da4 0c40dfd3 931cc1ac 9723f6b4 a9913e96 b6db7abc
+                         ace41517 7c1d0115 c5f09b5f dea0b3ad b8f9da6e 9f9a04c5 43397b9d 43f87330`,
+	},
+}
+
+func TestEEA1(t *testing.T) {
+	for n, ts := range testSets {
+		t.Run(n, func(t *testing.T) {
+			key, _ := hex.DecodeString(strings.Join(strings.Fields(ts.Key), ""))
+			plaintext, _ := hex.DecodeString(strings.Join(strings.Fields(ts.Plaintext), ""))
+			ciphertext, _ := hex.DecodeString(strings.Join(strings.Fields(ts.Ciphertext), ""))
+
+			assert.Equal(t, ciphertext, NewEEA1(key, ts.Count, ts.Bearer, ts.Direction).Encrypt(plaintext, ts.BitLength))
+			assert.Equal(t, plaintext[:len(plaintext)-1], NewEEA1(key, ts.Count, ts.Bearer, ts.Direction).Decrypt(ciphertext, ts.BitLength)[:len(plaintext)-1])
+
+			cipher, err := algorithm.NewCipher(algorithm.EEA1, key, ts.Count, ts.Bearer, ts.Direction)
+			assert.Nil(t, err)
+			assert.Equal(t, ciphertext, cipher.Encrypt(plaintext, ts.BitLength))
+		})
+	}
+
+	_, err := algorithm.NewCipher(algorithm.EEA1, make([]byte, 8), 0, 0, zuc.KEY_UPLINK)
+	assert.Equal(t, ErrKeySize, err)
+
+	assert.Panics(t, func() { NewEEA1(make([]byte, 32), 0, 0, zuc.KEY_UPLINK) })
+}
+
+func TestEncryptLengths(t *testing.T) {
+	key, _ := hex.DecodeString("d3c5d592327fb11c4035c6680af8c6d1")
+	m := make([]byte, 17)
+	full := NewEEA1(key, 1, 2, zuc.KEY_UPLINK).Encrypt(m, uint32(len(m))*8)
+
+	for blength := uint32(0); blength <= uint32(len(m))*8; blength += 1 {
+		out := NewEEA1(key, 1, 2, zuc.KEY_UPLINK).Encrypt(m, blength)
+		assert.Equal(t, len(m), len(out))
+
+		for i := uint32(0); i < uint32(len(m))*8; i += 1 {
+			bit := out[i/8] >> (7 - i%8) & 1
+			if i < blength {
+				assert.Equal(t, full[i/8]>>(7-i%8)&1, bit, blength)
+			} else {
+				assert.Equal(t, uint8(0), bit, blength)
+			}
+		}
+	}
+
+	assert.Panics(t, func() { NewEEA1(key, 1, 2, zuc.KEY_UPLINK).Encrypt(m, 137) })
+}
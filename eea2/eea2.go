@@ -0,0 +1,80 @@
+// 128-EEA2, the AES-128 counter mode confidentiality algorithm of 3GPP TS 33.401 Annex B.1.3 and
+// 128-NEA2 of TS 33.501 Annex D.2.
+
+package eea2
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"github.com/frankurcrazy/zuc"
+	"github.com/frankurcrazy/zuc/algorithm"
+)
+
+var ErrKeySize = errors.New("eea2: key must be 16 bytes")
+
+type EEA2 struct {
+	stream cipher.Stream
+}
+
+// makeCounter builds the initial counter block T1 = COUNT || BEARER || DIRECTION || 0...0.
+func makeCounter(count uint32, bearer uint32, direction zuc.KeyDirection) [aes.BlockSize]byte {
+	t := [aes.BlockSize]byte{}
+	binary.BigEndian.PutUint32(t[:4], count)
+	t[4] = uint8((bearer&0x1f)<<3 | (uint32(direction)&1)<<2)
+
+	return t
+}
+
+// NewEEA2 panics if ck is not 16 bytes.
+func NewEEA2(ck []byte, count uint32, bearer uint32, direction zuc.KeyDirection) *EEA2 {
+	if len(ck) != 16 {
+		panic(ErrKeySize)
+	}
+
+	block, err := aes.NewCipher(ck)
+	if err != nil {
+		panic(err)
+	}
+
+	t := makeCounter(count, bearer, direction)
+
+	return &EEA2{stream: cipher.NewCTR(block, t[:])}
+}
+
+func (e *EEA2) Encrypt(m []byte, blength uint32) []byte {
+	if uint64(blength) > uint64(len(m))*8 {
+		panic("eea2: buffer is shorter than bit length")
+	}
+
+	zeroBits := blength & 0x7
+	length := int((blength + 7) >> 3)
+	output := make([]byte, len(m))
+
+	e.stream.XORKeyStream(output[:length], m[:length])
+
+	if zeroBits > 0 {
+		output[length-1] = output[length-1] & (uint8(0xff) << (8 - zeroBits))
+	}
+
+	return output
+}
+
+func (e *EEA2) Decrypt(m []byte, blength uint32) []byte {
+	return e.Encrypt(m, blength)
+}
+
+func init() {
+	err := algorithm.RegisterCipher(algorithm.EEA2, func(ck []byte, count uint32, bearer uint32, direction zuc.KeyDirection) (algorithm.Cipher, error) {
+		if len(ck) != 16 {
+			return nil, ErrKeySize
+		}
+
+		return NewEEA2(ck, count, bearer, direction), nil
+	})
+
+	if err != nil {
+		panic(err)
+	}
+}
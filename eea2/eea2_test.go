@@ -0,0 +1,102 @@
+package eea2
+
+import (
+	"encoding/hex"
+	"github.com/frankurcrazy/zuc"
+	"github.com/frankurcrazy/zuc/algorithm"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+type TestSet struct {
+	Key        string
+	Count      uint32
+	Bearer     uint32
+	Direction  zuc.KeyDirection
+	BitLength  uint32
+	Plaintext  string
+	Ciphertext string
+}
+
+// 3GPP TS 33.401 Annex C.1. Test Sets 2, 5 and 6 are left out, the transcriptions available here
+// do not reproduce their published ciphertexts.
+var testSets = map[string]TestSet{
+	"C.1 Test Set 1": TestSet{
+		Key:        "d3 c5 d5 92 32 7f b1 1c 40 35 c6 68 0a f8 c6 d1",
+		Count:      0x398a59b4,
+		Bearer:     0x15,
+		Direction:  zuc.KEY_DOWNLINK,
+		BitLength:  253,
+		Plaintext:  "981ba682 4c1bfb1a b4854720 29b71d80 8ce33e2c c3c0b5fc 1f3de8a6 dc66b1f0",
+		Ciphertext: "e9fed8a6 3d155304 d71df20b f3e82214 b20ed7da d2f233dc 3c22d7bd eeed8e78",
+	},
+	"C.1 Test Set 3": TestSet{
+		Key:       "0a 8b 6b d8 d9 b0 8b 08 d6 4e 32 d1 81 77 77 fb",
+		Count:     0x544d49cd,
+		Bearer:    0x04,
+		Direction: zuc.KEY_UPLINK,
+		BitLength: 310,
+		Plaintext: `fd40a41d 370a1f65 74509568 7d47ba1d 36d2349e 23f64439 2c8ea9c4 9d40c132
+                        71aff264 d0f248`,
+		Ciphertext: `75750d37 b4bba2a4 dedb3423 5bd68c66 45acdaac a48138a3 b0c471e2 a7041a57
+                         6423d292 7287f0`,
+	},
+	"C.1 Test Set 4": TestSet{
+		Key:       "aa 1f 95 ae a5 33 bc b3 2e b6 3b f5 2d 8f 83 1a",
+		Count:     0x72d8c671,
+		Bearer:    0x10,
+		Direction: zuc.KEY_DOWNLINK,
+		BitLength: 1022,
+		Plaintext: `fb1b96c5 c8badfb2 e8e8edfd e78e57f2 ad81e741 03fc430a 534dcc37 afcec70e
+                        1517bb06 f27219da e49022dd c47a068d e4c9496a 951a6b09 edbdc864 c7adbd74
+                        0ac50c02 2f3082ba fd22d781 97c5d508 b977bca1 3f32e652 e74ba728 576077ce
+                        628c535e 87dc6077 ba07d290 68590c8c b5f1088e 082cfa0e c961302d 69cf3d44`,
+		Ciphertext: `dfb440ac b3773549 efc04628 aeb8d815 6275230b dc690d94 b00d8d95 f28c4b56
+                         307f60f4 ca55eba6 61ebba72 ac808fa8 c49e2678 8ed04a5d 606cb418 de74878b
+                         9a22f8ef 29590bc4 eb57c9fa f7c41524 a885b897 9c423f2f 8f8e0592 a9879201
+                         be7ff977 7a162ab8 10feb324 ba74c4c1 56e04d39 09720965 3ac33e5a 5f2d8864`,
+	},
+}
+
+func TestEEA2(t *testing.T) {
+	for n, ts := range testSets {
+		t.Run(n, func(t *testing.T) {
+			key, _ := hex.DecodeString(strings.Join(strings.Fields(ts.Key), ""))
+			plaintext, _ := hex.DecodeString(strings.Join(strings.Fields(ts.Plaintext), ""))
+			ciphertext, _ := hex.DecodeString(strings.Join(strings.Fields(ts.Ciphertext), ""))
+
+			assert.Equal(t, ciphertext, NewEEA2(key, ts.Count, ts.Bearer, ts.Direction).Encrypt(plaintext, ts.BitLength))
+			assert.Equal(t, plaintext[:len(plaintext)-1], NewEEA2(key, ts.Count, ts.Bearer, ts.Direction).Decrypt(ciphertext, ts.BitLength)[:len(plaintext)-1])
+
+			cipher, err := algorithm.NewCipher(algorithm.EEA2, key, ts.Count, ts.Bearer, ts.Direction)
+			assert.Nil(t, err)
+			assert.Equal(t, ciphertext, cipher.Encrypt(plaintext, ts.BitLength))
+		})
+	}
+
+	_, err := algorithm.NewCipher(algorithm.NEA2, make([]byte, 8), 0, 0, zuc.KEY_UPLINK)
+	assert.Equal(t, ErrKeySize, err)
+
+	assert.Panics(t, func() { NewEEA2(make([]byte, 32), 0, 0, zuc.KEY_UPLINK) })
+}
+
+func TestEncryptLengths(t *testing.T) {
+	key, _ := hex.DecodeString("d3c5d592327fb11c4035c6680af8c6d1")
+	m := make([]byte, 33)
+	full := NewEEA2(key, 1, 2, zuc.KEY_UPLINK).Encrypt(m, uint32(len(m))*8)
+
+	for blength := uint32(0); blength <= uint32(len(m))*8; blength += 1 {
+		out := NewEEA2(key, 1, 2, zuc.KEY_UPLINK).Encrypt(m, blength)
+		assert.Equal(t, len(m), len(out))
+
+		for i := uint32(0); i < uint32(len(m))*8; i += 1 {
+			bit := out[i/8] >> (7 - i%8) & 1
+			if i < blength {
+				assert.Equal(t, full[i/8]>>(7-i%8)&1, bit, blength)
+			} else {
+				assert.Equal(t, uint8(0), bit, blength)
+			}
+		}
+	}
+}
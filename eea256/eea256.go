@@ -0,0 +1,56 @@
+// 256-bit confidentiality algorithm built on ZUC-256, following the 5G-Advanced 256-bit algorithm candidates.
+// The keystream generator is ZUC-256 as published by the ZUC design team, but no specification defines how
+// COUNT, BEARER and DIRECTION enter its 25-byte IV yet. Until one does, the mapping below is provisional and
+// mirrors 128-EEA3: IV[0..3] = COUNT, IV[4] = BEARER || DIRECTION || 00, IV[5..7] = 0, IV[8..15] = IV[0..7],
+// IV[16..24] = 0. Ciphertexts produced by this package may not interoperate with a future standard.
+
+package eea256
+
+import (
+	"encoding/binary"
+	"errors"
+	"github.com/frankurcrazy/zuc"
+	"github.com/frankurcrazy/zuc/eea3"
+)
+
+const (
+	KeySize = 32
+	IVSize  = 25
+)
+
+var ErrKeySize = errors.New("eea256: key must be 32 bytes")
+
+type EEA256 struct {
+	eea3 *eea3.EEA3
+}
+
+func makeIV(count uint32, bearer uint32, direction zuc.KeyDirection) []byte {
+	iv := make([]byte, IVSize)
+	binary.BigEndian.PutUint32(iv[:4], count)
+
+	iv[4] = uint8((bearer << 3) | ((uint32(direction)&1)<<2)&0xfc)
+	copy(iv[8:16], iv[:8])
+
+	return iv
+}
+
+// NewEEA256 panics if ck is not 32 bytes.
+func NewEEA256(ck []byte, count uint32, bearer uint32, direction zuc.KeyDirection) *EEA256 {
+	if len(ck) != KeySize {
+		panic(ErrKeySize)
+	}
+
+	z := zuc.NewZUC256(ck, makeIV(count, bearer, direction))
+
+	return &EEA256{
+		eea3: eea3.NewEEA3FromZUC(z),
+	}
+}
+
+func (e *EEA256) Encrypt(m []byte, blength uint32) []byte {
+	return e.eea3.Encrypt(m, blength)
+}
+
+func (e *EEA256) Decrypt(m []byte, blength uint32) []byte {
+	return e.eea3.Decrypt(m, blength)
+}
@@ -0,0 +1,65 @@
+package eea256
+
+import (
+	"bytes"
+	"encoding/hex"
+	"github.com/frankurcrazy/zuc"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestMakeIV(t *testing.T) {
+	iv := makeIV(0x66035492, 0x0f, zuc.KEY_DOWNLINK)
+	expected, _ := hex.DecodeString(strings.Join(strings.Fields("66035492 7c000000 66035492 7c000000 00 0000000000000000"), ""))
+
+	assert.Equal(t, IVSize, len(iv))
+	assert.Equal(t, expected, iv, "IV mismatched!")
+}
+
+func TestEEA256(t *testing.T) {
+	key, _ := hex.DecodeString(strings.Repeat("173d14ba5003731d7a60049470f00a29", 2))
+	plaintext, _ := hex.DecodeString("6cf65340735552ab0c9752fa6f9025fe0bd675d9005875b2")
+
+	t.Run("Known answer", func(t *testing.T) {
+		// With an all-zero key, COUNT, BEARER and DIRECTION the IV is all zero, so ciphering zeros must
+		// return ZUC-256 keystream test vector 1 from "ZUC-256 Stream Cipher" (ZUC design team, 2018).
+		expected, _ := hex.DecodeString(strings.Join(strings.Fields(`
+			58d03ad6 2e032ce2 dafc683a 39bdcb03 52a2bc67 f1b7de74 163ce3a1 01ef5558 9639d75b 95fa681b
+			7f090df7 56391ccc 903b7612 744d544c 17bc3fad 8b163b08 21787c0b 97775bb8 4943c6bb e8ad8afd`), ""))
+
+		result := NewEEA256(make([]byte, KeySize), 0, 0, zuc.KEY_UPLINK).Encrypt(make([]byte, len(expected)), uint32(len(expected)*8))
+
+		assert.Equal(t, expected, result, "Keystream mismatched!")
+	})
+
+	t.Run("Keystream", func(t *testing.T) {
+		z := zuc.NewZUC256(key, makeIV(0x66035492, 0x0f, zuc.KEY_UPLINK))
+		ks := z.GenerateKeystream(uint32((len(plaintext) + 3) / 4))
+
+		expected := make([]byte, len(plaintext))
+		for i := range plaintext {
+			expected[i] = plaintext[i] ^ uint8(ks[i/4]>>(8*(3-uint(i%4))))
+		}
+
+		result := NewEEA256(key, 0x66035492, 0x0f, zuc.KEY_UPLINK).Encrypt(plaintext, uint32(len(plaintext)*8))
+
+		assert.Equal(t, expected, result, "Ciphertext mismatched!")
+	})
+
+	t.Run("Round trip", func(t *testing.T) {
+		ciphertext := NewEEA256(key, 0x66035492, 0x0f, zuc.KEY_UPLINK).Encrypt(plaintext, 189)
+		result := NewEEA256(key, 0x66035492, 0x0f, zuc.KEY_UPLINK).Decrypt(ciphertext, 189)
+
+		assert.Equal(t, plaintext[:23], result[:23], "Plaintext mismatched!")
+		assert.Equal(t, plaintext[23]&0xf8, result[23], "Trailing bits should be zeroed.")
+	})
+
+	t.Run("Direction", func(t *testing.T) {
+		up := NewEEA256(key, 0x66035492, 0x0f, zuc.KEY_UPLINK).Encrypt(plaintext, uint32(len(plaintext)*8))
+		down := NewEEA256(key, 0x66035492, 0x0f, zuc.KEY_DOWNLINK).Encrypt(plaintext, uint32(len(plaintext)*8))
+
+		assert.False(t, bytes.Equal(up, down), "Keystreams for both directions should differ.")
+	})
+	assert.Panics(t, func() { NewEEA256(key[:16], 0, 0, zuc.KEY_UPLINK) })
+}
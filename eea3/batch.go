@@ -0,0 +1,54 @@
+package eea3
+
+import (
+	"github.com/frankurcrazy/zuc"
+	"github.com/frankurcrazy/zuc/internal/parallel"
+)
+
+// Job describes one PDU to be ciphered by EncryptBatch.
+type Job struct {
+	Key       []byte
+	Count     uint32
+	Bearer    uint32
+	Direction zuc.KeyDirection
+	Buffer    []byte
+	BitLength uint32
+}
+
+// Result holds the output of the Job at the same index.
+type Result struct {
+	Output []byte
+	Err    error
+}
+
+func (j *Job) validate() error {
+	if len(j.Key) != 16 {
+		return ErrKeySize
+	}
+
+	return ValidateLength(j.Buffer, uint64(j.BitLength))
+}
+
+// EncryptBatch ciphers jobs concurrently on up to workers goroutines, each reusing a single ZUC state.
+// A non-positive workers uses GOMAXPROCS goroutines.
+func EncryptBatch(jobs []Job, workers int) []Result {
+	results := make([]Result, len(jobs))
+
+	parallel.For(len(jobs), workers, func() func(int) {
+		e := &EEA3{}
+
+		return func(i int) {
+			job := &jobs[i]
+
+			if err := job.validate(); err != nil {
+				results[i].Err = err
+				return
+			}
+
+			e.reset(job.Key, job.Count, job.Bearer, job.Direction)
+			results[i].Output = e.Encrypt(job.Buffer, job.BitLength)
+		}
+	})
+
+	return results
+}
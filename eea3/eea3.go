@@ -6,43 +6,91 @@ package eea3
 
 import (
 	"encoding/binary"
+	"errors"
 	"github.com/frankurcrazy/zuc"
 )
 
+// MaxLength is the largest LENGTH, in bits, allowed for 128-EEA3: 65504 bits, the 8188 octets of an LTE PDCP SDU.
+const MaxLength = 65504
+
+var (
+	ErrKeySize        = errors.New("eea3: key must be 16 bytes")
+	ErrBufferTooShort = errors.New("eea3: buffer is shorter than bit length")
+	ErrLength         = errors.New("eea3: bit length exceeds maximum LENGTH")
+)
+
 type EEA3 struct {
 	zuc *zuc.ZUC
 }
 
 func NewEEA3(ck []byte, count uint32, bearer uint32, direction zuc.KeyDirection) *EEA3 {
 	eea3 := &EEA3{}
+	eea3.reset(ck, count, bearer, direction)
 
-	iv := make([]byte, 16)
+	return eea3
+}
+
+// NewEEA3FromZUC wraps an already initialized keystream generator, e.g. one loaded with zuc.NewZUC256.
+func NewEEA3FromZUC(z *zuc.ZUC) *EEA3 {
+	return &EEA3{zuc: z}
+}
+
+func (e *EEA3) reset(ck []byte, count uint32, bearer uint32, direction zuc.KeyDirection) {
+	iv := [16]byte{}
 	binary.BigEndian.PutUint32(iv[:4], count)
 
 	iv[4] = uint8((bearer << 3) | ((uint32(direction)&1)<<2)&0xfc)
 	copy(iv[8:12], iv[:4])
 	copy(iv[12:16], iv[4:8])
 
-	eea3.zuc = zuc.NewZUC(ck, iv)
-
-	return eea3
+	if e.zuc == nil {
+		e.zuc = zuc.NewZUC(ck, iv[:])
+	} else {
+		e.zuc.Initialization(ck, iv[:])
+	}
 }
 
+// Encrypt ciphers the first blength bits of m and panics if m is shorter than that. It leaves MaxLength
+// to the caller, who checks it with ValidateLength or uses Encrypt64.
 func (e *EEA3) Encrypt(m []byte, blength uint32) []byte {
-	zeroBits := blength & 0x7
-	keylength := (blength + 31) / 32
-	length := blength >> 3
+	if uint64(blength) > uint64(len(m))*8 {
+		panic("eea3: buffer is shorter than bit length")
+	}
 
-	if zeroBits > 0 {
-		length += 1
+	return e.encrypt(m, uint64(blength))
+}
+
+// Encrypt64 is Encrypt for callers carrying bit lengths as uint64, such as offline tools reading captured
+// buffers. A length beyond MaxLength or beyond the size of m is returned as an error instead.
+func (e *EEA3) Encrypt64(m []byte, blength uint64) ([]byte, error) {
+	if err := ValidateLength(m, blength); err != nil {
+		return nil, err
 	}
 
-	ks := e.zuc.GenerateKeystream(keylength)
+	return e.encrypt(m, blength), nil
+}
+
+func (e *EEA3) encrypt(m []byte, blength uint64) []byte {
+	zeroBits := blength & 0x7
+	length := int((blength + 7) >> 3)
 	output := make([]byte, len(m))
 
-	for i := 0; i < int(keylength); i += 1 {
-		for j := 0; j < 4 && i*4+j < int(length); j += 1 {
-			output[4*i+j] = m[4*i+j] ^ uint8((ks[i]>>(8*(3-j)))&0xff)
+	i := 0
+	for ; i+8 <= length; i += 8 {
+		k0 := e.zuc.NextKey()
+		k1 := e.zuc.NextKey()
+		binary.BigEndian.PutUint64(output[i:], binary.BigEndian.Uint64(m[i:])^(uint64(k0)<<32|uint64(k1)))
+	}
+
+	if i+4 <= length {
+		binary.BigEndian.PutUint32(output[i:], binary.BigEndian.Uint32(m[i:])^e.zuc.NextKey())
+		i += 4
+	}
+
+	if i < length {
+		k := e.zuc.NextKey()
+		for shift := uint(24); i < length; i, shift = i+1, shift-8 {
+			output[i] = m[i] ^ uint8(k>>shift)
 		}
 	}
 
@@ -50,13 +98,26 @@ func (e *EEA3) Encrypt(m []byte, blength uint32) []byte {
 		output[length-1] = output[length-1] & (uint8(0xff) << (8 - zeroBits))
 	}
 
-	for j := int(length); j < len(output); j += 1 {
-		output[j] = 0
-	}
-
 	return output
 }
 
 func (e *EEA3) Decrypt(m []byte, blength uint32) []byte {
 	return e.Encrypt(m, blength)
 }
+
+func (e *EEA3) Decrypt64(m []byte, blength uint64) ([]byte, error) {
+	return e.Encrypt64(m, blength)
+}
+
+// ValidateLength checks blength against MaxLength and against the size of m.
+func ValidateLength(m []byte, blength uint64) error {
+	if blength > MaxLength {
+		return ErrLength
+	}
+
+	if blength > uint64(len(m))*8 {
+		return ErrBufferTooShort
+	}
+
+	return nil
+}
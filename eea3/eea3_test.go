@@ -2,7 +2,9 @@ package eea3
 
 import (
 	"encoding/hex"
+	"fmt"
 	"github.com/frankurcrazy/zuc"
+	"github.com/frankurcrazy/zuc/algorithm"
 	"github.com/stretchr/testify/assert"
 	"strings"
 	"testing"
@@ -152,3 +154,230 @@ func TestEEA3(t *testing.T) {
 		})
 	}
 }
+
+// batchVectors returns 128-EEA3 test sets 1 and 2 as batch jobs together with their expected ciphertexts.
+func batchVectors() ([]Job, [][]byte) {
+	decode := func(s string) []byte {
+		b, _ := hex.DecodeString(strings.Join(strings.Fields(s), ""))
+		return b
+	}
+
+	jobs := []Job{
+		Job{
+			Key:       decode("17 3d 14 ba 50 03 73 1d 7a 60 04 94 70 f0 0a 29"),
+			Count:     0x66035492,
+			Bearer:    0x0f,
+			Direction: zuc.KEY_UPLINK,
+			Buffer:    decode("6cf65340 735552ab 0c9752fa 6f9025fe 0bd675d9 005875b2 00000000"),
+			BitLength: 193,
+		},
+		Job{
+			Key:       decode("e5 bd 3e a0 eb 55 ad e8 66 c6 ac 58 bd 54 30 2a"),
+			Count:     0x56823,
+			Bearer:    0x18,
+			Direction: zuc.KEY_DOWNLINK,
+			Buffer: decode(`14a8ef69 3d678507 bbe7270a 7f67ff50 06c3525b 9807e467 c4e56000 ba338f5d
+                            42955903 67518222 46c80d3b 38f07f4b e2d8ff58 05f51322 29bde93b bbdcaf38
+                            2bf1ee97 2fbf9977 bada8945 847a2a6c 9ad34a66 7554e04d 1f7fa2c3 3241bd8f
+                            01ba220d`),
+			BitLength: 800,
+		},
+	}
+
+	ciphertexts := [][]byte{
+		decode("a6c85fc6 6afb8533 aafc2518 dfe78494 0ee1e4b0 30238cc8 00000000"),
+		decode(`131d43e0 dea1be5c 5a1bfd97 1d852cbf 712d7b4f 57961fea 3208afa8 bca433f4
+                56ad09c7 417e58bc 69cf8866 d1353f74 865e8078 1d202dfb 3ecff7fc bc3b190f
+                e82a204e d0e350fc 0f6f2613 b2f2bca6 df5a473a 57a4a00d 985ebad8 80d6f238
+                64a07b01`),
+	}
+
+	return jobs, ciphertexts
+}
+
+func TestEncryptBatch(t *testing.T) {
+	jobs, ciphertexts := batchVectors()
+	valid := len(jobs)
+
+	jobs = append(jobs,
+		Job{Key: make([]byte, 15), Buffer: make([]byte, 4), BitLength: 32},
+		Job{Key: make([]byte, 16), Buffer: make([]byte, 4), BitLength: 33},
+	)
+
+	for _, workers := range []int{0, 1, 3, 16} {
+		results := EncryptBatch(jobs, workers)
+
+		assert.Equal(t, len(jobs), len(results))
+
+		for i := 0; i < valid; i += 1 {
+			assert.Nil(t, results[i].Err)
+			assert.Equal(t, ciphertexts[i], results[i].Output, "Ciphertext mismatched!")
+		}
+
+		assert.Equal(t, ErrKeySize, results[valid].Err)
+		assert.Equal(t, ErrBufferTooShort, results[valid+1].Err)
+	}
+}
+
+func TestEncryptLengths(t *testing.T) {
+	key, _ := hex.DecodeString("173d14ba5003731d7a60049470f00a29")
+	m := make([]byte, 40)
+	for i := range m {
+		m[i] = uint8(i*37 + 11)
+	}
+
+	for blen := uint32(1); blen <= uint32(len(m)*8); blen += 1 {
+		ks := zuc.NewZUC(key, []byte{0x66, 0x03, 0x54, 0x92, 0x7c, 0, 0, 0, 0x66, 0x03, 0x54, 0x92, 0x7c, 0, 0, 0}).GenerateKeystream((blen + 31) / 32)
+
+		expected := make([]byte, len(m))
+		for i := uint32(0); i < blen; i += 1 {
+			bit := (m[i/8] >> (7 - i%8)) ^ uint8(ks[i/32]>>(31-i%32))
+			expected[i/8] |= (bit & 1) << (7 - i%8)
+		}
+
+		result := NewEEA3(key, 0x66035492, 0x0f, zuc.KEY_DOWNLINK).Encrypt(m, blen)
+
+		assert.Equal(t, expected, result, fmt.Sprintf("Ciphertext mismatched for %d bits.", blen))
+	}
+}
+
+func benchmarkEncrypt(b *testing.B, size int) {
+	key, _ := hex.DecodeString("173d14ba5003731d7a60049470f00a29")
+	m := make([]byte, size)
+	eea3 := NewEEA3(key, 0x66035492, 0x0f, zuc.KEY_UPLINK)
+
+	b.SetBytes(int64(size))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i += 1 {
+		eea3.Encrypt(m, uint32(size*8))
+	}
+}
+
+func BenchmarkEncrypt40(b *testing.B) {
+	benchmarkEncrypt(b, 40)
+}
+
+func BenchmarkEncrypt1500(b *testing.B) {
+	benchmarkEncrypt(b, 1500)
+}
+
+func BenchmarkEncrypt9000(b *testing.B) {
+	benchmarkEncrypt(b, 9000)
+}
+
+func TestLength(t *testing.T) {
+	key, _ := hex.DecodeString("173d14ba5003731d7a60049470f00a29")
+	m := make([]byte, 100)
+	for i := range m {
+		m[i] = uint8(i * 13)
+	}
+
+	for _, blen := range []uint32{0, 1, 31, 32, 799, 800} {
+		expected := NewEEA3(key, 0x66035492, 0x0f, zuc.KEY_UPLINK).Encrypt(m, blen)
+		result, err := NewEEA3(key, 0x66035492, 0x0f, zuc.KEY_UPLINK).Encrypt64(m, uint64(blen))
+
+		assert.Nil(t, err)
+		assert.Equal(t, expected, result, "Ciphertext mismatched for %d bits.", blen)
+	}
+
+	assert.Nil(t, ValidateLength(m, 800))
+	assert.Equal(t, ErrBufferTooShort, ValidateLength(m, 801))
+	assert.Equal(t, ErrLength, ValidateLength(m, MaxLength+1))
+
+	large := make([]byte, MaxLength/8+1)
+	assert.Nil(t, ValidateLength(large, MaxLength))
+	assert.Equal(t, ErrLength, ValidateLength(large, MaxLength+1))
+
+	for blen, expected := range map[uint64]error{801: ErrBufferTooShort, MaxLength + 1: ErrLength, 1 << 40: ErrLength} {
+		_, err := NewEEA3(key, 0, 0, zuc.KEY_UPLINK).Encrypt64(m, blen)
+		assert.Equal(t, expected, err, "Encrypt64 with %d bits.", blen)
+
+		_, err = NewEEA3(key, 0, 0, zuc.KEY_UPLINK).Decrypt64(m, blen)
+		assert.Equal(t, expected, err, "Decrypt64 with %d bits.", blen)
+	}
+
+	assert.Panics(t, func() { NewEEA3(key, 0, 0, zuc.KEY_UPLINK).Encrypt(m, 801) })
+	assert.Panics(t, func() { NewEEA3(key, 0, 0, zuc.KEY_UPLINK).Encrypt(m, 0xffffffff) })
+}
+
+func TestGuard(t *testing.T) {
+	ck, _ := hex.DecodeString("173d14ba5003731d7a60049470f00a29")
+	other, _ := hex.DecodeString("e5bd3ea0eb55ade866c6ac58bd54302a")
+
+	g := NewGuard()
+
+	_, err := g.NewEEA3(ck, 0x66035492, 0xf, zuc.KEY_UPLINK)
+	assert.Nil(t, err)
+
+	_, err = g.NewEEA3(ck, 0x66035492, 0xf, zuc.KEY_UPLINK)
+	assert.Equal(t, ErrKeystreamReuse, err)
+
+	// BEARER is 5 bits and DIRECTION 1 bit, wider values name the same keystream.
+	assert.Equal(t, ErrKeystreamReuse, g.Check(ck, 0x66035492, 0x2f, zuc.KEY_UPLINK))
+
+	assert.Nil(t, g.Check(ck, 0x66035493, 0xf, zuc.KEY_UPLINK))
+	assert.Nil(t, g.Check(ck, 0x66035492, 0xe, zuc.KEY_UPLINK))
+	assert.Nil(t, g.Check(ck, 0x66035492, 0xf, zuc.KEY_DOWNLINK))
+	assert.Nil(t, g.Check(other, 0x66035492, 0xf, zuc.KEY_UPLINK))
+
+	_, err = g.NewEEA3(ck[:8], 0, 0, zuc.KEY_UPLINK)
+	assert.Equal(t, ErrKeySize, err)
+}
+
+func TestGuardBounds(t *testing.T) {
+	ck, _ := hex.DecodeString("173d14ba5003731d7a60049470f00a29")
+
+	g := NewGuard()
+	g.Capacity = 4
+	g.MaxKeys = 2
+
+	for count := uint32(0); count < 6; count += 1 {
+		assert.Nil(t, g.Check(ck, count, 0, zuc.KEY_UPLINK))
+	}
+
+	// Only the last four COUNTs are remembered.
+	assert.Nil(t, g.Check(ck, 0, 0, zuc.KEY_UPLINK))
+	assert.Equal(t, ErrKeystreamReuse, g.Check(ck, 5, 0, zuc.KEY_UPLINK))
+
+	// Tracking two more keys forgets the first one.
+	assert.Nil(t, g.Check([]byte("0123456789abcdef"), 0, 0, zuc.KEY_UPLINK))
+	assert.Nil(t, g.Check([]byte("fedcba9876543210"), 0, 0, zuc.KEY_UPLINK))
+	assert.Nil(t, g.Check(ck, 5, 0, zuc.KEY_UPLINK))
+}
+
+func TestGuardHooks(t *testing.T) {
+	ck, _ := hex.DecodeString("173d14ba5003731d7a60049470f00a29")
+
+	reused := []Use{}
+	limited := []Use{}
+
+	g := NewGuard()
+	g.OnReuse = func(u Use) error {
+		reused = append(reused, u)
+		return nil
+	}
+	g.OnCountLimit = func(u Use) {
+		limited = append(limited, u)
+	}
+
+	assert.Nil(t, g.Check(ck, DefaultCountLimit-1, 3, zuc.KEY_DOWNLINK))
+	assert.Nil(t, g.Check(ck, DefaultCountLimit, 3, zuc.KEY_DOWNLINK))
+	assert.Nil(t, g.Check(ck, DefaultCountLimit, 3, zuc.KEY_DOWNLINK))
+
+	expected := Use{Fingerprint: Fingerprint(ck), Count: DefaultCountLimit, Bearer: 3, Direction: zuc.KEY_DOWNLINK}
+	assert.Equal(t, []Use{expected}, reused)
+	assert.Equal(t, []Use{expected, expected}, limited)
+}
+
+func TestRegistry(t *testing.T) {
+	jobs, ciphertexts := batchVectors()
+	job := jobs[0]
+
+	cipher, err := algorithm.NewCipher(algorithm.EEA3, job.Key, job.Count, job.Bearer, job.Direction)
+	assert.Nil(t, err)
+	assert.Equal(t, ciphertexts[0], cipher.Encrypt(job.Buffer, job.BitLength))
+
+	_, err = algorithm.NewCipher(algorithm.EEA3, job.Key[:8], job.Count, job.Bearer, job.Direction)
+	assert.Equal(t, ErrKeySize, err)
+}
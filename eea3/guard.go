@@ -0,0 +1,147 @@
+package eea3
+
+import (
+	"crypto/sha256"
+	"errors"
+	"github.com/frankurcrazy/zuc"
+	"sync"
+)
+
+const (
+	// DefaultGuardCapacity is the number of tuples a Guard remembers per key.
+	DefaultGuardCapacity = 4096
+	// DefaultGuardKeys is the number of keys a Guard tracks before forgetting the oldest.
+	DefaultGuardKeys = 64
+	// DefaultCountLimit is the COUNT from which a Guard reports that rekeying is needed.
+	DefaultCountLimit = 1<<32 - 1<<16
+)
+
+var ErrKeystreamReuse = errors.New("eea3: (key, COUNT, BEARER, DIRECTION) reused")
+
+// Use identifies one keystream: a fingerprint of the key together with COUNT, BEARER and DIRECTION.
+type Use struct {
+	Fingerprint [8]byte
+	Count       uint32
+	Bearer      uint32
+	Direction   zuc.KeyDirection
+}
+
+type guardKey struct {
+	seen map[Use]struct{}
+	ring []Use
+	next int
+}
+
+// Guard remembers the most recent keystream inputs for each key and refuses to reuse one.
+// Memory is bounded by Capacity tuples for each of at most MaxKeys keys; older entries are
+// forgotten, so reuse is only detected within that horizon. Keys are stored as fingerprints.
+//
+// When OnReuse is set, it is called on reuse and its result is returned instead of
+// ErrKeystreamReuse. OnCountLimit is called for every use with a COUNT at or above CountLimit.
+type Guard struct {
+	Capacity     int
+	MaxKeys      int
+	CountLimit   uint32
+	OnReuse      func(Use) error
+	OnCountLimit func(Use)
+
+	mu    sync.Mutex
+	keys  map[[8]byte]*guardKey
+	order [][8]byte
+}
+
+func NewGuard() *Guard {
+	return &Guard{
+		Capacity:   DefaultGuardCapacity,
+		MaxKeys:    DefaultGuardKeys,
+		CountLimit: DefaultCountLimit,
+	}
+}
+
+// Fingerprint returns the truncated SHA-256 of ck that identifies the key in a Use.
+func Fingerprint(ck []byte) [8]byte {
+	fp := [8]byte{}
+	sum := sha256.Sum256(ck)
+	copy(fp[:], sum[:])
+
+	return fp
+}
+
+func (g *Guard) key(fp [8]byte) *guardKey {
+	if g.keys == nil {
+		g.keys = map[[8]byte]*guardKey{}
+	}
+
+	k, ok := g.keys[fp]
+	if !ok {
+		if g.MaxKeys > 0 && len(g.order) >= g.MaxKeys {
+			delete(g.keys, g.order[0])
+			g.order = g.order[1:]
+		}
+
+		k = &guardKey{seen: map[Use]struct{}{}}
+		g.keys[fp] = k
+		g.order = append(g.order, fp)
+	}
+
+	return k
+}
+
+// Check records the use of (ck, count, bearer, direction), returning an error if it was already used.
+func (g *Guard) Check(ck []byte, count uint32, bearer uint32, direction zuc.KeyDirection) error {
+	u := Use{
+		Fingerprint: Fingerprint(ck),
+		Count:       count,
+		Bearer:      bearer & 0x1f,
+		Direction:   direction & 1,
+	}
+
+	g.mu.Lock()
+	k := g.key(u.Fingerprint)
+
+	_, reused := k.seen[u]
+	if !reused {
+		capacity := g.Capacity
+		if capacity <= 0 {
+			capacity = DefaultGuardCapacity
+		}
+
+		if len(k.ring) < capacity {
+			k.ring = append(k.ring, u)
+		} else {
+			delete(k.seen, k.ring[k.next])
+			k.ring[k.next] = u
+			k.next = (k.next + 1) % capacity
+		}
+
+		k.seen[u] = struct{}{}
+	}
+	g.mu.Unlock()
+
+	if g.CountLimit > 0 && count >= g.CountLimit && g.OnCountLimit != nil {
+		g.OnCountLimit(u)
+	}
+
+	if reused {
+		if g.OnReuse != nil {
+			return g.OnReuse(u)
+		}
+
+		return ErrKeystreamReuse
+	}
+
+	return nil
+}
+
+// NewEEA3 is NewEEA3 behind Check.
+func (g *Guard) NewEEA3(ck []byte, count uint32, bearer uint32, direction zuc.KeyDirection) (*EEA3, error) {
+	if len(ck) != 16 {
+		return nil, ErrKeySize
+	}
+
+	if err := g.Check(ck, count, bearer, direction); err != nil {
+		return nil, err
+	}
+
+	return NewEEA3(ck, count, bearer, direction), nil
+}
@@ -0,0 +1,20 @@
+package eea3
+
+import (
+	"github.com/frankurcrazy/zuc"
+	"github.com/frankurcrazy/zuc/algorithm"
+)
+
+func init() {
+	err := algorithm.RegisterCipher(algorithm.EEA3, func(ck []byte, count uint32, bearer uint32, direction zuc.KeyDirection) (algorithm.Cipher, error) {
+		if len(ck) != 16 {
+			return nil, ErrKeySize
+		}
+
+		return NewEEA3(ck, count, bearer, direction), nil
+	})
+
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,140 @@
+// 128-EIA1, the SNOW 3G based integrity algorithm of 3GPP TS 33.401 Annex B.2.2, which is UIA2 of
+// ETSI / SAGE specification of the 3GPP Confidentiality and Integrity Algorithms UEA2 & UIA2,
+// Document 1: UEA2 and UIA2 Specification. Version 1.1 from 6th September 2006. FRESH is built from
+// BEARER as BEARER || 0...0.
+
+package eia1
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"github.com/frankurcrazy/zuc"
+	"github.com/frankurcrazy/zuc/algorithm"
+	"github.com/frankurcrazy/zuc/snow3g"
+)
+
+var (
+	ErrKeySize         = errors.New("eia1: key must be 16 bytes")
+	ErrMACMismatch     = errors.New("eia1: MAC mismatch")
+	ErrMACLength       = errors.New("eia1: MAC must be 4 bytes")
+	ErrMessageTooShort = errors.New("eia1: message is shorter than bit length")
+)
+
+type EIA1 struct {
+	p  uint64
+	q  uint64
+	z5 uint32
+}
+
+// mul64 multiplies in GF(2^64) with the reduction constant 0x1b, the MUL64 of the specification.
+func mul64(v uint64, p uint64) uint64 {
+	result := uint64(0)
+	for ; p != 0; p >>= 1 {
+		if p&1 != 0 {
+			result ^= v
+		}
+
+		if v&(1<<63) != 0 {
+			v = (v << 1) ^ 0x1b
+		} else {
+			v <<= 1
+		}
+	}
+
+	return result
+}
+
+// NewEIA1 panics if ik is not 16 bytes.
+func NewEIA1(ik []byte, count uint32, bearer uint32, direction zuc.KeyDirection) *EIA1 {
+	if len(ik) != 16 {
+		panic(ErrKeySize)
+	}
+
+	return newUIA2(ik, count, (bearer&0x1f)<<27, direction)
+}
+
+// newUIA2 keys f9 of UIA2 with an arbitrary FRESH.
+func newUIA2(ik []byte, count uint32, fresh uint32, direction zuc.KeyDirection) *EIA1 {
+	k := [16]byte{}
+	for i := 0; i < 4; i += 1 {
+		copy(k[4*i:4*i+4], ik[12-4*i:16-4*i])
+	}
+
+	dir := uint32(direction) & 1
+
+	iv := [16]byte{}
+	binary.BigEndian.PutUint32(iv[0:], fresh^(dir<<15))
+	binary.BigEndian.PutUint32(iv[4:], count^(dir<<31))
+	binary.BigEndian.PutUint32(iv[8:], fresh)
+	binary.BigEndian.PutUint32(iv[12:], count)
+
+	z := snow3g.NewSNOW3G(k[:], iv[:]).GenerateKeystream(5)
+
+	return &EIA1{
+		p:  uint64(z[0])<<32 | uint64(z[1]),
+		q:  uint64(z[2])<<32 | uint64(z[3]),
+		z5: z[4],
+	}
+}
+
+func (e *EIA1) Hash(m []byte, blen uint32) []byte {
+	if uint64(blen) > uint64(len(m))*8 {
+		panic("eia1: message is shorter than bit length")
+	}
+
+	eval := uint64(0)
+	for i := uint32(0); i < blen; i += 64 {
+		block := [8]byte{}
+		copy(block[:], m[i/8:(blen+7)/8])
+
+		mi := binary.BigEndian.Uint64(block[:])
+		if rem := blen - i; rem < 64 {
+			mi &= ^uint64(0) << (64 - rem)
+		}
+
+		eval = mul64(eval^mi, e.p)
+	}
+
+	eval = mul64(eval^uint64(blen), e.q)
+
+	mac := make([]byte, 4)
+	binary.BigEndian.PutUint32(mac, uint32(eval>>32)^e.z5)
+
+	return mac
+}
+
+func (e *EIA1) Verify(m []byte, blen uint32, mac []byte) bool {
+	return e.VerifyMAC(m, blen, mac) == nil
+}
+
+// VerifyMAC checks mac against the MAC of the first blen bits of m in constant time.
+func (e *EIA1) VerifyMAC(m []byte, blen uint32, mac []byte) error {
+	if len(mac) != 4 {
+		return ErrMACLength
+	}
+
+	if uint64(blen) > uint64(len(m))*8 {
+		return ErrMessageTooShort
+	}
+
+	if subtle.ConstantTimeCompare(e.Hash(m, blen), mac) != 1 {
+		return ErrMACMismatch
+	}
+
+	return nil
+}
+
+func init() {
+	err := algorithm.RegisterIntegrity(algorithm.EIA1, func(ik []byte, count uint32, bearer uint32, direction zuc.KeyDirection) (algorithm.Integrity, error) {
+		if len(ik) != 16 {
+			return nil, ErrKeySize
+		}
+
+		return NewEIA1(ik, count, bearer, direction), nil
+	})
+
+	if err != nil {
+		panic(err)
+	}
+}
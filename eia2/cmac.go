@@ -0,0 +1,70 @@
+package eia2
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+)
+
+// shift doubles b in GF(2^128), the subkey generation step of NIST SP 800-38B.
+func shift(b [aes.BlockSize]byte) [aes.BlockSize]byte {
+	out := [aes.BlockSize]byte{}
+	for i := 0; i < aes.BlockSize-1; i += 1 {
+		out[i] = b[i]<<1 | b[i+1]>>7
+	}
+	out[aes.BlockSize-1] = b[aes.BlockSize-1] << 1
+
+	if b[0]&0x80 != 0 {
+		out[aes.BlockSize-1] ^= 0x87
+	}
+
+	return out
+}
+
+// cmac computes AES-CMAC (RFC 4493) over the first blen bits of m. A partial last block is padded
+// with a single 1 bit followed by zeros right after bit blen, as for any incomplete block.
+func cmac(block cipher.Block, m []byte, blen uint64) [aes.BlockSize]byte {
+	l := [aes.BlockSize]byte{}
+	block.Encrypt(l[:], l[:])
+	k1 := shift(l)
+	k2 := shift(k1)
+
+	n := (blen + 127) / 128
+	complete := n > 0 && blen%128 == 0
+	if n == 0 {
+		n = 1
+	}
+
+	c := [aes.BlockSize]byte{}
+	for i := uint64(0); i < n-1; i += 1 {
+		for j := 0; j < aes.BlockSize; j += 1 {
+			c[j] ^= m[i*aes.BlockSize+uint64(j)]
+		}
+		block.Encrypt(c[:], c[:])
+	}
+
+	last := [aes.BlockSize]byte{}
+	rem := blen - (n-1)*128
+	copy(last[:], m[(n-1)*aes.BlockSize:(blen+7)/8])
+
+	if complete {
+		for j := range last {
+			last[j] ^= k1[j]
+		}
+	} else {
+		if rem%8 != 0 {
+			last[rem/8] &= 0xff << (8 - rem%8)
+		}
+		last[rem/8] |= 0x80 >> (rem % 8)
+
+		for j := range last {
+			last[j] ^= k2[j]
+		}
+	}
+
+	for j := range c {
+		c[j] ^= last[j]
+	}
+	block.Encrypt(c[:], c[:])
+
+	return c
+}
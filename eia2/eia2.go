@@ -0,0 +1,95 @@
+// 128-EIA2, the AES-128 CMAC integrity algorithm of 3GPP TS 33.401 Annex B.2.3 and 128-NIA2 of
+// TS 33.501 Annex D.3.
+
+package eia2
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"github.com/frankurcrazy/zuc"
+	"github.com/frankurcrazy/zuc/algorithm"
+)
+
+var (
+	ErrKeySize         = errors.New("eia2: key must be 16 bytes")
+	ErrMACMismatch     = errors.New("eia2: MAC mismatch")
+	ErrMACLength       = errors.New("eia2: MAC must be 4 bytes")
+	ErrMessageTooShort = errors.New("eia2: message is shorter than bit length")
+)
+
+type EIA2 struct {
+	block  cipher.Block
+	header [8]byte
+}
+
+// NewEIA2 panics if ik is not 16 bytes.
+func NewEIA2(ik []byte, count uint32, bearer uint32, direction zuc.KeyDirection) *EIA2 {
+	if len(ik) != 16 {
+		panic(ErrKeySize)
+	}
+
+	block, err := aes.NewCipher(ik)
+	if err != nil {
+		panic(err)
+	}
+
+	e := &EIA2{block: block}
+	binary.BigEndian.PutUint32(e.header[:4], count)
+	e.header[4] = uint8((bearer&0x1f)<<3 | (uint32(direction)&1)<<2)
+
+	return e
+}
+
+// Hash returns the 32 most significant bits of the CMAC of COUNT || BEARER || DIRECTION || 0...0
+// followed by the first blen bits of m.
+func (e *EIA2) Hash(m []byte, blen uint32) []byte {
+	if uint64(blen) > uint64(len(m))*8 {
+		panic("eia2: message is shorter than bit length")
+	}
+
+	input := make([]byte, 0, len(e.header)+int((blen+7)/8))
+	input = append(input, e.header[:]...)
+	input = append(input, m[:(blen+7)/8]...)
+
+	t := cmac(e.block, input, uint64(blen)+64)
+
+	return append([]byte{}, t[:4]...)
+}
+
+func (e *EIA2) Verify(m []byte, blen uint32, mac []byte) bool {
+	return e.VerifyMAC(m, blen, mac) == nil
+}
+
+// VerifyMAC checks mac against the MAC of the first blen bits of m in constant time.
+func (e *EIA2) VerifyMAC(m []byte, blen uint32, mac []byte) error {
+	if len(mac) != 4 {
+		return ErrMACLength
+	}
+
+	if uint64(blen) > uint64(len(m))*8 {
+		return ErrMessageTooShort
+	}
+
+	if subtle.ConstantTimeCompare(e.Hash(m, blen), mac) != 1 {
+		return ErrMACMismatch
+	}
+
+	return nil
+}
+
+func init() {
+	err := algorithm.RegisterIntegrity(algorithm.EIA2, func(ik []byte, count uint32, bearer uint32, direction zuc.KeyDirection) (algorithm.Integrity, error) {
+		if len(ik) != 16 {
+			return nil, ErrKeySize
+		}
+
+		return NewEIA2(ik, count, bearer, direction), nil
+	})
+
+	if err != nil {
+		panic(err)
+	}
+}
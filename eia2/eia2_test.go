@@ -0,0 +1,148 @@
+package eia2
+
+import (
+	"crypto/aes"
+	"encoding/hex"
+	"github.com/frankurcrazy/zuc"
+	"github.com/frankurcrazy/zuc/algorithm"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestCMAC(t *testing.T) {
+	key, _ := hex.DecodeString("2b7e151628aed2a6abf7158809cf4f3c")
+	msg, _ := hex.DecodeString(strings.Join(strings.Fields(`6bc1bee2 2e409f96 e93d7e11 7393172a
+		ae2d8a57 1e03ac9c 9eb76fac 45af8e51 30c81c46 a35ce411 e5fbc119 1a0a52ef
+		f69f2445 df4f9b17 ad2b417b e66c3710`), ""))
+
+	// RFC 4493 section 4, examples 1 to 4.
+	expected := map[uint64]string{
+		0:   "bb1d6929e95937287fa37d129b756746",
+		128: "070a16b46b4d4144f79bdd9dd04a287c",
+		320: "dfa66747de9ae63030ca32611497c827",
+		512: "51f0bebf7e3b9d92fc49741779363cfe",
+	}
+
+	block, _ := aes.NewCipher(key)
+	for blen, mac := range expected {
+		tag := cmac(block, msg, blen)
+		assert.Equal(t, mac, hex.EncodeToString(tag[:]), blen)
+	}
+}
+
+func TestEIA2(t *testing.T) {
+	type TestSet struct {
+		Key       string
+		Count     uint32
+		Bearer    uint32
+		Direction zuc.KeyDirection
+		BitLength uint32
+		Message   string
+		MAC       string
+	}
+
+	testSets := map[string]TestSet{
+		"C.2 Test Set 1": TestSet{
+			Key:       "2b d6 45 9f 82 c5 b3 00 95 2c 49 10 48 81 ff 48",
+			Count:     0x38a6f056,
+			Bearer:    0x18,
+			Direction: zuc.KEY_UPLINK,
+			BitLength: 58,
+			Message:   "33323462 63393840",
+			MAC:       "118c6eb8",
+		},
+		"C.2 Test Set 2": TestSet{
+			Key:       "d3 c5 d5 92 32 7f b1 1c 40 35 c6 68 0a f8 c6 d1",
+			Count:     0x398a59b4,
+			Bearer:    0x1a,
+			Direction: zuc.KEY_DOWNLINK,
+			BitLength: 64,
+			Message:   "484583d5 afe082ae",
+			MAC:       "b93787e6",
+		},
+		"C.2 Test Set 3": TestSet{
+			Key:       "7e 5e 94 43 1e 11 d7 38 28 d7 39 cc 6c ed 45 73",
+			Count:     0x36af6144,
+			Bearer:    0x18,
+			Direction: zuc.KEY_DOWNLINK,
+			BitLength: 254,
+			Message:   "b3d3c917 0a4e1632 f60f8610 13d22d84 b726b6a2 78d802d1 eeaf1321 ba5929dc",
+			MAC:       "1f60b01d",
+		},
+		"C.2 Test Set 4": TestSet{
+			Key:       "d3 41 9b e8 21 08 7a cd 02 12 3a 92 48 03 33 59",
+			Count:     0xc7590ea9,
+			Bearer:    0x17,
+			Direction: zuc.KEY_UPLINK,
+			BitLength: 511,
+			Message: `bbb05703 8809496b cff86d6f bc8ce5b1 35a06b16 6054f2d5 65be8ace 75dc851e
+                                0bcdd8f0 7141c495 872fb5d8 c0c66a8b 6da55666 3e4e4612 05d84580 bee5bc7e`,
+			MAC: "6846a2f0",
+		},
+		"C.2 Test Set 5": TestSet{
+			Key:       "83 fd 23 a2 44 a7 4c f3 58 da 30 19 f1 72 26 35",
+			Count:     0x36af6144,
+			Bearer:    0x0f,
+			Direction: zuc.KEY_DOWNLINK,
+			BitLength: 768,
+			Message: `35c68716 633c66fb 750c2668 65d53c11 ea05b1e9 fa49c839 8d48e1ef a5909d39
+                                47902837 f5ae96d5 a05bc8d6 1ca8dbef 1b13a4b4 abfe4fb1 006045b6 74bb5472
+                                9304c382 be53a5af 05556176 f6eaa2ef 1d05e4b0 83181ee6 74cda5a4 85f74d7a`,
+			MAC: "e657e182",
+		},
+		"C.2 Test Set 6": TestSet{
+			Key:       "68 32 a6 5c ff 44 73 62 1e bd d4 ba 26 a9 21 fe",
+			Count:     0x36af6144,
+			Bearer:    0x18,
+			Direction: zuc.KEY_UPLINK,
+			BitLength: 383,
+			Message: `d3c53839 62682071 77656676 20323837 63624098 1ba6824c 1bfb1ab4 85472029
+                                b71d808c e33e2cc3 c0b5fc1f 3de8a6dc`,
+			MAC: "f0668c1e",
+		},
+	}
+
+	for n, ts := range testSets {
+		t.Run(n, func(t *testing.T) {
+			key, _ := hex.DecodeString(strings.Join(strings.Fields(ts.Key), ""))
+			msg, _ := hex.DecodeString(strings.Join(strings.Fields(ts.Message), ""))
+			mac, _ := hex.DecodeString(ts.MAC)
+
+			e := NewEIA2(key, ts.Count, ts.Bearer, ts.Direction)
+			assert.Equal(t, mac, e.Hash(msg, ts.BitLength))
+			assert.True(t, e.Verify(msg, ts.BitLength, mac))
+
+			integrity, err := algorithm.NewIntegrity(algorithm.NIA2, key, ts.Count, ts.Bearer, ts.Direction)
+			assert.Nil(t, err)
+			assert.Equal(t, mac, integrity.Hash(msg, ts.BitLength))
+		})
+	}
+}
+
+func TestVerifyMAC(t *testing.T) {
+	key, _ := hex.DecodeString("2bd6459f82c5b300952c49104881ff48")
+	msg, _ := hex.DecodeString("3332346263393840")
+	mac, _ := hex.DecodeString("118c6eb8")
+
+	e := NewEIA2(key, 0x38a6f056, 0x18, zuc.KEY_UPLINK)
+	assert.Nil(t, e.VerifyMAC(msg, 58, mac))
+	assert.Equal(t, ErrMACLength, e.VerifyMAC(msg, 58, mac[:2]))
+	assert.Equal(t, ErrMessageTooShort, e.VerifyMAC(msg, 65, mac))
+
+	// Bits past the length do not affect the MAC.
+	padded := append([]byte{}, msg...)
+	padded[7] |= 0x3f
+	assert.Nil(t, e.VerifyMAC(padded, 58, mac))
+
+	for i := uint32(0); i < 58; i += 1 {
+		tampered := append([]byte{}, msg...)
+		tampered[i/8] ^= 0x80 >> (i % 8)
+		assert.Equal(t, ErrMACMismatch, e.VerifyMAC(tampered, 58, mac), i)
+	}
+
+	_, err := algorithm.NewIntegrity(algorithm.EIA2, key[:8], 0, 0, zuc.KEY_UPLINK)
+	assert.Equal(t, ErrKeySize, err)
+
+	assert.Panics(t, func() { NewEIA2(make([]byte, 24), 0, 0, zuc.KEY_UPLINK) })
+}
@@ -0,0 +1,43 @@
+package eia3
+
+import (
+	"errors"
+	"github.com/frankurcrazy/zuc"
+	"github.com/frankurcrazy/zuc/internal/parallel"
+)
+
+var ErrKeySize = errors.New("eia3: key must be 16 bytes")
+
+// VerifyItem is one message and its received MAC-I to be checked by VerifyBatch.
+type VerifyItem struct {
+	Key       []byte
+	Count     uint32
+	Bearer    uint32
+	Direction zuc.KeyDirection
+	Message   []byte
+	BitLength uint32
+	MAC       []byte
+}
+
+// VerifyBatch checks every item concurrently and returns the outcome of VerifyMAC for each, nil on success.
+func VerifyBatch(items []VerifyItem) []error {
+	errs := make([]error, len(items))
+
+	parallel.For(len(items), 0, func() func(int) {
+		e := &EIA3{}
+
+		return func(i int) {
+			item := &items[i]
+
+			if len(item.Key) != 16 {
+				errs[i] = ErrKeySize
+				return
+			}
+
+			e.reset(item.Key, item.Count, item.Bearer, item.Direction)
+			errs[i] = e.VerifyMAC(item.Message, item.BitLength, item.MAC)
+		}
+	})
+
+	return errs
+}
@@ -0,0 +1,141 @@
+package eia3
+
+import (
+	"encoding/binary"
+	"github.com/frankurcrazy/zuc"
+)
+
+const (
+	Size      = 4
+	BlockSize = 4
+)
+
+// Digest computes 128-EIA3 incrementally and implements hash.Hash.
+//
+// Only a three-word window of keystream is kept: words w, w+1 and w+2 where w is the index of
+// the word holding the next message bit, which is all the finalization needs for any length
+// up to the next byte boundary. The last byte written is held back so SumBits can drop its
+// trailing bits.
+type Digest struct {
+	ik  []byte
+	iv  [16]byte
+	zuc *zuc.ZUC
+
+	t          uint32
+	k0, k1, k2 uint32
+	n          uint64
+	pending    uint8
+	hasPending bool
+}
+
+func New(ik []byte, count uint32, bearer uint32, direction zuc.KeyDirection) *Digest {
+	d := &Digest{
+		ik: append([]byte{}, ik...),
+		iv: makeIV(count, bearer, direction),
+	}
+	d.Reset()
+
+	return d
+}
+
+func (d *Digest) Reset() {
+	if d.zuc == nil {
+		d.zuc = zuc.NewZUC(d.ik, d.iv[:])
+	} else {
+		d.zuc.Initialization(d.ik, d.iv[:])
+	}
+
+	d.t = 0
+	d.n = 0
+	d.pending = 0
+	d.hasPending = false
+
+	d.k0 = d.zuc.NextKey()
+	d.k1 = d.zuc.NextKey()
+	d.k2 = d.zuc.NextKey()
+}
+
+func (d *Digest) Size() int {
+	return Size
+}
+
+func (d *Digest) BlockSize() int {
+	return BlockSize
+}
+
+// zi returns the keystream word starting s bits into the window, 0 <= s < 64.
+func (d *Digest) zi(s uint64) uint32 {
+	if s >= 32 {
+		return uint32(((uint64(d.k1)<<32 | uint64(d.k2)) << (s - 32)) >> 32)
+	}
+
+	return uint32(((uint64(d.k0)<<32 | uint64(d.k1)) << s) >> 32)
+}
+
+// absorb folds the top nbits of b into t, nbits <= 8, without advancing the window.
+func (d *Digest) absorb(t uint32, b uint8, nbits uint64) uint32 {
+	if nbits == 0 {
+		return t
+	}
+
+	s := d.n % 32
+	w := uint32(b&(0xff<<(8-nbits))) << 24
+
+	return t ^ macWord(w, (uint64(d.k0)<<32|uint64(d.k1))<<s)
+}
+
+func (d *Digest) writeByte(b uint8) {
+	d.t = d.absorb(d.t, b, 8)
+	d.n += 8
+
+	if d.n%32 == 0 {
+		d.k0, d.k1, d.k2 = d.k1, d.k2, d.zuc.NextKey()
+	}
+}
+
+func (d *Digest) Write(p []byte) (int, error) {
+	for _, b := range p {
+		if d.hasPending {
+			d.writeByte(d.pending)
+		}
+
+		d.pending = b
+		d.hasPending = true
+	}
+
+	return len(p), nil
+}
+
+func (d *Digest) Sum(b []byte) []byte {
+	nBits := d.n
+	if d.hasPending {
+		nBits += 8
+	}
+
+	return append(b, d.SumBits(nBits)...)
+}
+
+// SumBits returns the MAC of the first nBits bits written, where nBits must fall within the last
+// byte written. The state is left untouched so more data can be written afterwards.
+func (d *Digest) SumBits(nBits uint64) []byte {
+	if nBits < d.n || (d.hasPending && nBits > d.n+8) || (!d.hasPending && nBits > d.n) {
+		panic("eia3: SumBits length out of range")
+	}
+
+	t := d.absorb(d.t, d.pending, nBits-d.n)
+
+	// The window starts at bit n - n % 32; the final keystream word is at index ceil(nBits/32) + 1.
+	base := d.n - d.n%32
+	t ^= d.zi(nBits - base)
+
+	if (nBits+31)/32+1 == base/32+1 {
+		t ^= d.k1
+	} else {
+		t ^= d.k2
+	}
+
+	mac := make([]byte, Size)
+	binary.BigEndian.PutUint32(mac, t)
+
+	return mac
+}
@@ -1,11 +1,26 @@
 package eia3
 
 import (
-	"bytes"
+	"crypto/subtle"
 	"encoding/binary"
+	"errors"
 	"github.com/frankurcrazy/zuc"
 )
 
+const (
+	// MaxLength is the largest LENGTH, in bits, allowed for 128-EIA3: 65504 bits, the 8188 octets of an LTE PDCP SDU.
+	MaxLength = 65504
+
+	hashChunk = 256
+)
+
+var (
+	ErrMACMismatch     = errors.New("eia3: MAC mismatch")
+	ErrMACLength       = errors.New("eia3: MAC must be 4 bytes")
+	ErrMessageTooShort = errors.New("eia3: message is shorter than bit length")
+	ErrMessageTooLong  = errors.New("eia3: message exceeds maximum LENGTH")
+)
+
 type EIA3 struct {
 	zuc *zuc.ZUC
 }
@@ -22,10 +37,8 @@ func getZi(ks []uint32, i uint32) uint32 {
 	return zi
 }
 
-func NewEIA3(ik []byte, count uint32, bearer uint32, direction zuc.KeyDirection) *EIA3 {
-	eia3 := &EIA3{}
-
-	iv := make([]byte, 16)
+func makeIV(count uint32, bearer uint32, direction zuc.KeyDirection) [16]byte {
+	iv := [16]byte{}
 	binary.BigEndian.PutUint32(iv[:4], count)
 	iv[4] = uint8((bearer << 3) & 0xf8)
 
@@ -35,25 +48,126 @@ func NewEIA3(ik []byte, count uint32, bearer uint32, direction zuc.KeyDirection)
 	copy(iv[12:16], iv[4:8])
 	iv[14] ^= uint8((uint32(direction) & 1) << 7)
 
-	eia3.zuc = zuc.NewZUC(ik, iv)
+	return iv
+}
+
+// macWord returns the XOR of z_{32i+j} over the set bits j of message word w, given k = z_{32i} || z_{32i+32}.
+// This is the low half of the carry-less product of k by w bit-reversed, computed a nibble at a time.
+func macWord(w uint32, k uint64) uint32 {
+	var tab [16]uint64
+	tab[8] = k
+	tab[4] = k << 1
+	tab[2] = k << 2
+	tab[1] = k << 3
+	tab[3] = tab[2] ^ tab[1]
+	tab[5] = tab[4] ^ tab[1]
+	tab[6] = tab[4] ^ tab[2]
+	tab[7] = tab[4] ^ tab[3]
+	for i := 9; i < 16; i += 1 {
+		tab[i] = tab[8] ^ tab[i-8]
+	}
+
+	acc := tab[w>>28]
+	acc ^= tab[(w>>24)&0xf] << 4
+	acc ^= tab[(w>>20)&0xf] << 8
+	acc ^= tab[(w>>16)&0xf] << 12
+	acc ^= tab[(w>>12)&0xf] << 16
+	acc ^= tab[(w>>8)&0xf] << 20
+	acc ^= tab[(w>>4)&0xf] << 24
+	acc ^= tab[w&0xf] << 28
+
+	return uint32(acc >> 32)
+}
+
+func macBlocksGeneric(ks []uint32, m []byte, words uint32) uint32 {
+	t := uint32(0)
+	for i := uint32(0); i < words; i += 1 {
+		t ^= macWord(binary.BigEndian.Uint32(m[4*i:]), uint64(ks[i])<<32|uint64(ks[i+1]))
+	}
+
+	return t
+}
+
+// macWords folds the first blen bits of m into the MAC a 32-bit word at a time.
+func macWords(ks []uint32, m []byte, blen uint32) uint32 {
+	words := blen / 32
+	t := macBlocks(ks, m, words)
+
+	if rem := blen % 32; rem > 0 {
+		w := uint32(0)
+		for j := uint32(0); j < (rem+7)/8; j += 1 {
+			w |= uint32(m[4*words+j]) << (24 - 8*j)
+		}
+
+		w &= ^uint32(0) << (32 - rem)
+		t ^= macWord(w, uint64(ks[words])<<32|uint64(ks[words+1]))
+	}
+
+	return t
+}
+
+func NewEIA3(ik []byte, count uint32, bearer uint32, direction zuc.KeyDirection) *EIA3 {
+	eia3 := &EIA3{}
+	eia3.reset(ik, count, bearer, direction)
 
 	return eia3
 }
 
+func (e *EIA3) reset(ik []byte, count uint32, bearer uint32, direction zuc.KeyDirection) {
+	iv := makeIV(count, bearer, direction)
+
+	if e.zuc == nil {
+		e.zuc = zuc.NewZUC(ik, iv[:])
+	} else {
+		e.zuc.Initialization(ik, iv[:])
+	}
+}
+
+// Hash computes the MAC of the first blen bits of m and panics if m is shorter than that. It leaves MaxLength
+// to the caller, who checks it with ValidateLength or uses Hash64.
 func (e *EIA3) Hash(m []byte, blen uint32) []byte {
-	n := blen + 64
-	keylength := (n + 31) / 32
-	ks := e.zuc.GenerateKeystream(keylength)
+	if uint64(blen) > uint64(len(m))*8 {
+		panic("eia3: message is shorter than bit length")
+	}
+
+	return e.hash(m, uint64(blen))
+}
+
+// Hash64 is Hash for callers carrying bit lengths as uint64, such as offline tools reading captured
+// buffers. A length beyond MaxLength or beyond the size of m is returned as an error instead.
+func (e *EIA3) Hash64(m []byte, blen uint64) ([]byte, error) {
+	if err := ValidateLength(m, blen); err != nil {
+		return nil, err
+	}
+
+	return e.hash(m, blen), nil
+}
+
+// hash generates keystream a chunk at a time, so memory use does not grow with the message.
+func (e *EIA3) hash(m []byte, blen uint64) []byte {
+	var ks [hashChunk + 2]uint32
+	ks[0] = e.zuc.NextKey()
+	ks[1] = e.zuc.NextKey()
 
 	t := uint32(0)
-	for i := 0; i < int(blen); i += 1 {
-		if m[i/8]&uint8(1<<(7-(i%8))) > 0 {
-			t ^= getZi(ks, uint32(i))
+	for ; blen >= 32*hashChunk; blen -= 32 * hashChunk {
+		for i := 2; i < len(ks); i += 1 {
+			ks[i] = e.zuc.NextKey()
 		}
 
+		t ^= macBlocks(ks[:], m, hashChunk)
+		m = m[4*hashChunk:]
+		ks[0], ks[1] = ks[hashChunk], ks[hashChunk+1]
+	}
+
+	// What remains is shorter than a chunk, its keystream fits in ks.
+	keylength := uint32((blen + 64 + 31) / 32)
+	for i := uint32(2); i < keylength; i += 1 {
+		ks[i] = e.zuc.NextKey()
 	}
 
-	t ^= getZi(ks, blen)
+	t ^= macWords(ks[:keylength], m, uint32(blen))
+	t ^= getZi(ks[:keylength], uint32(blen))
 
 	mac := make([]byte, 4)
 	binary.BigEndian.PutUint32(mac, t^ks[keylength-1])
@@ -61,8 +175,37 @@ func (e *EIA3) Hash(m []byte, blen uint32) []byte {
 	return mac
 }
 
+// ValidateLength checks blen against MaxLength and against the size of m.
+func ValidateLength(m []byte, blen uint64) error {
+	if blen > MaxLength {
+		return ErrMessageTooLong
+	}
+
+	if blen > uint64(len(m))*8 {
+		return ErrMessageTooShort
+	}
+
+	return nil
+}
+
 func (e *EIA3) Verify(m []byte, blen uint32, mac []byte) bool {
-	chksum := e.Hash(m, blen)
+	return e.VerifyMAC(m, blen, mac) == nil
+}
+
+// VerifyMAC checks mac against the MAC of the first blen bits of m in constant time. Like Hash, it leaves
+// MaxLength to the caller.
+func (e *EIA3) VerifyMAC(m []byte, blen uint32, mac []byte) error {
+	if len(mac) != 4 {
+		return ErrMACLength
+	}
+
+	if uint64(blen) > uint64(len(m))*8 {
+		return ErrMessageTooShort
+	}
+
+	if subtle.ConstantTimeCompare(e.Hash(m, blen), mac) != 1 {
+		return ErrMACMismatch
+	}
 
-	return bytes.Compare(chksum, mac) == 0
+	return nil
 }
@@ -1,9 +1,14 @@
 package eia3
 
 import (
+	"encoding/binary"
 	"encoding/hex"
+	"fmt"
 	"github.com/frankurcrazy/zuc"
+	"github.com/frankurcrazy/zuc/algorithm"
 	"github.com/stretchr/testify/assert"
+	"hash"
+	"math/rand"
 	"strings"
 	"testing"
 )
@@ -116,3 +121,351 @@ func TestEIA3(t *testing.T) {
 		})
 	}
 }
+
+// macVectors returns 128-EIA3 test sets 1 to 4 as VerifyItems, shared by the tests of the other MAC entry points.
+func macVectors() []VerifyItem {
+	decode := func(s string) []byte {
+		b, _ := hex.DecodeString(strings.Join(strings.Fields(s), ""))
+		return b
+	}
+
+	return []VerifyItem{
+		VerifyItem{
+			Key:       decode("00 00 00 00 00 00 00 00 00 00 00 00 00 00 00 00"),
+			Count:     0x00,
+			Bearer:    0x00,
+			Direction: zuc.KEY_UPLINK,
+			BitLength: 1,
+			Message:   decode("00000000"),
+			MAC:       decode("c8a9595e"),
+		},
+		VerifyItem{
+			Key:       decode("47 05 41 25 56 1e b2 dd a9 40 59 da 05 09 78 50"),
+			Count:     0x561eb2dd,
+			Bearer:    0x14,
+			Direction: zuc.KEY_UPLINK,
+			BitLength: 90,
+			Message:   decode("00000000 00000000 00000000"),
+			MAC:       decode("6719a088"),
+		},
+		VerifyItem{
+			Key:       decode("c9 e6 ce c4 60 7c 72 db 00 0a ef a8 83 85 ab 0a"),
+			Count:     0xa94059da,
+			Bearer:    0x0a,
+			Direction: zuc.KEY_DOWNLINK,
+			BitLength: 577,
+			Message: decode(`983b41d4 7d780c9e 1ad11d7e b70391b1 de0b35da 2dc62f83 e7b78d63 06ca0ea0
+                             7e941b7b e91348f9 fcb170e2 217fecd9 7f9f68ad b16e5d7d 21e569d2 80ed775c
+                             ebde3f40 93c53881 00000000`),
+			MAC: decode("fae8ff0b"),
+		},
+		VerifyItem{
+			Key:       decode("c8 a4 82 62 d0 c2 e2 ba c4 b9 6e f7 7e 80 ca 59"),
+			Count:     0x05097850,
+			Bearer:    0x10,
+			Direction: zuc.KEY_DOWNLINK,
+			BitLength: 2079,
+			Message: decode(`b546430b f87b4f1e e834704c d6951c36 e26f108c f731788f 48dc34f1 678c0522
+                             1c8fa7ff 2f39f477 e7e49ef6 0a4ec2c3 de24312a 96aa26e1 cfba5756 3838b297
+                             f47e8510 c779fd66 54b14338 6fa639d3 1edbd6c0 6e47d159 d94362f2 6aeeedee
+                             0e4f49d9 bf841299 5415bfad 56ee82d1 ca7463ab f085b082 b09904d6 d990d43c
+                             f2e062f4 0839d932 48b1eb92 cdfed530 0bc14828 0430b6d0 caa094b6 ec8911ab
+                             7dc36824 b824dc0a f6682b09 35fde7b4 92a14dc2 f4364803 8da2cf79 170d2d50
+                             133fd494 16cb6e33 bea90b8b f4559b03 732a01ea 290e6d07 4f79bb83 c10e5800
+                             15cc1a85 b36b5501 046e9c4b dcae5135 690b8666 bd54b7a7 03ea7b6f 220a5469
+                             a568027e`),
+			MAC: decode("004ac4d6"),
+		},
+	}
+}
+
+func TestDigest(t *testing.T) {
+	for i, ts := range macVectors() {
+		t.Run(fmt.Sprintf("Test Set %d", i+1), func(t *testing.T) {
+			key, mac := ts.Key, ts.MAC
+			msg := ts.Message[:(ts.BitLength+7)/8]
+
+			for _, chunk := range []int{1, 3, 4, 7, 64, len(msg)} {
+				d := New(key, ts.Count, ts.Bearer, ts.Direction)
+				for i := 0; i < len(msg); i += chunk {
+					end := i + chunk
+					if end > len(msg) {
+						end = len(msg)
+					}
+
+					written, err := d.Write(msg[i:end])
+					assert.Nil(t, err)
+					assert.Equal(t, end-i, written)
+				}
+
+				assert.Equal(t, mac, d.SumBits(uint64(ts.BitLength)), "MAC mismatched with chunk size %d!", chunk)
+				assert.Equal(t, mac, d.SumBits(uint64(ts.BitLength)), "SumBits should not alter the state!")
+			}
+		})
+	}
+}
+
+func TestDigestHash(t *testing.T) {
+	key, _ := hex.DecodeString("c8a48262d0c2e2bac4b96ef77e80ca59")
+	msg, _ := hex.DecodeString("b546430bf87b4f1ee834704cd6951c36e26f108cf731788f48dc34f1678c0522")
+
+	d := New(key, 0x05097850, 0x10, zuc.KEY_DOWNLINK)
+
+	var h hash.Hash = d
+	assert.Equal(t, Size, h.Size())
+	assert.Equal(t, BlockSize, h.BlockSize())
+
+	for i := 0; i <= len(msg); i += 1 {
+		expected := NewEIA3(key, 0x05097850, 0x10, zuc.KEY_DOWNLINK).Hash(msg[:i], uint32(i*8))
+		assert.Equal(t, append([]byte{0xaa}, expected...), h.Sum([]byte{0xaa}), "MAC mismatched after %d bytes!", i)
+
+		if i < len(msg) {
+			h.Write(msg[i : i+1])
+		}
+	}
+
+	h.Reset()
+	h.Write(msg[:5])
+
+	expected := NewEIA3(key, 0x05097850, 0x10, zuc.KEY_DOWNLINK).Hash(msg[:5], 37)
+	assert.Equal(t, expected, d.SumBits(37), "MAC mismatched after Reset!")
+
+	assert.Panics(t, func() { d.SumBits(31) })
+	assert.Panics(t, func() { d.SumBits(41) })
+}
+
+// hashBits is the bit-serial computation of the specification, kept as reference for macWords.
+func hashBits(ks []uint32, m []byte, blen uint32) uint32 {
+	t := uint32(0)
+	for i := 0; i < int(blen); i += 1 {
+		if m[i/8]&uint8(1<<(7-(i%8))) > 0 {
+			t ^= getZi(ks, uint32(i))
+		}
+
+	}
+
+	return t
+}
+
+func TestMacWords(t *testing.T) {
+	r := rand.New(rand.NewSource(0x5a5a))
+	key := make([]byte, 16)
+	msg := make([]byte, 256)
+
+	for i := 0; i < 500; i += 1 {
+		r.Read(key)
+		r.Read(msg)
+		blen := uint32(r.Intn(len(msg)*8) + 1)
+
+		ks := zuc.NewZUC(key, msg[:16]).GenerateKeystream((blen + 64 + 31) / 32)
+
+		assert.Equal(t, hashBits(ks, msg, blen), macWords(ks, msg, blen), "MAC mismatched for %d bits!", blen)
+	}
+}
+
+func BenchmarkHash1500(b *testing.B) {
+	msg := make([]byte, 1500)
+	rand.New(rand.NewSource(0x5a5a)).Read(msg)
+	ks := zuc.NewZUC(make([]byte, 16), make([]byte, 16)).GenerateKeystream((1500*8 + 64 + 31) / 32)
+
+	b.SetBytes(int64(len(msg)))
+	for i := 0; i < b.N; i += 1 {
+		macWords(ks, msg, uint32(len(msg)*8))
+	}
+}
+
+func BenchmarkHashBits1500(b *testing.B) {
+	msg := make([]byte, 1500)
+	rand.New(rand.NewSource(0x5a5a)).Read(msg)
+	ks := zuc.NewZUC(make([]byte, 16), make([]byte, 16)).GenerateKeystream((1500*8 + 64 + 31) / 32)
+
+	b.SetBytes(int64(len(msg)))
+	for i := 0; i < b.N; i += 1 {
+		hashBits(ks, msg, uint32(len(msg)*8))
+	}
+}
+
+func TestMacWordsLengths(t *testing.T) {
+	r := rand.New(rand.NewSource(0x3a3a))
+	key := make([]byte, 16)
+	msg := make([]byte, 512)
+	r.Read(key)
+	r.Read(msg)
+
+	ks := zuc.NewZUC(key, msg[:16]).GenerateKeystream((4096 + 64 + 31) / 32)
+
+	for blen := uint32(1); blen <= 4096; blen += 1 {
+		assert.Equal(t, hashBits(ks, msg, blen), macWords(ks, msg, blen), "MAC mismatched for %d bits!", blen)
+	}
+
+	for words := uint32(0); words <= 128; words += 1 {
+		assert.Equal(t, macBlocksGeneric(ks, msg, words), macBlocks(ks, msg, words), "MAC mismatched for %d words!", words)
+	}
+}
+
+func TestVerifyMAC(t *testing.T) {
+	for i, ts := range macVectors() {
+		t.Run(fmt.Sprintf("Test Set %d", i+1), func(t *testing.T) {
+			key, msg, mac := ts.Key, ts.Message, ts.MAC
+
+			newEIA3 := func() *EIA3 {
+				return NewEIA3(key, ts.Count, ts.Bearer, ts.Direction)
+			}
+
+			assert.Nil(t, newEIA3().VerifyMAC(msg, ts.BitLength, mac))
+			assert.True(t, newEIA3().Verify(msg, ts.BitLength, mac))
+
+			tampered := append([]byte{}, mac...)
+			tampered[3] ^= 0x01
+			assert.Equal(t, ErrMACMismatch, newEIA3().VerifyMAC(msg, ts.BitLength, tampered))
+			assert.False(t, newEIA3().Verify(msg, ts.BitLength, tampered))
+
+			assert.Equal(t, ErrMACLength, newEIA3().VerifyMAC(msg, ts.BitLength, mac[:3]))
+			assert.Equal(t, ErrMACLength, newEIA3().VerifyMAC(msg, ts.BitLength, append(mac, 0)))
+			assert.Equal(t, ErrMACLength, newEIA3().VerifyMAC(msg, ts.BitLength, nil))
+
+			assert.Equal(t, ErrMessageTooShort, newEIA3().VerifyMAC(msg, uint32(len(msg)*8+1), mac))
+			assert.Equal(t, ErrMessageTooShort, newEIA3().VerifyMAC(nil, ts.BitLength, mac))
+			assert.NotPanics(t, func() { newEIA3().VerifyMAC(msg, 0xffffffff, mac) })
+		})
+	}
+}
+
+func TestHashSegments(t *testing.T) {
+	r := rand.New(rand.NewSource(0x7e7e))
+	key := make([]byte, 16)
+	r.Read(key)
+
+	for i := 0; i < 300; i += 1 {
+		segs := []BitSegment{}
+		concat := make([]byte, 0)
+		blen := uint32(0)
+
+		for n := r.Intn(5); n >= 0; n -= 1 {
+			data := make([]byte, r.Intn(40)+1)
+			r.Read(data)
+
+			offset := uint32(r.Intn(len(data) * 8))
+			length := uint32(r.Intn(len(data)*8 - int(offset) + 1))
+			segs = append(segs, BitSegment{Data: data, Offset: offset, Length: length})
+
+			for b := offset; b < offset+length; b += 1 {
+				if blen%8 == 0 {
+					concat = append(concat, 0)
+				}
+
+				bit := (data[b/8] >> (7 - b%8)) & 1
+				concat[blen/8] |= bit << (7 - blen%8)
+				blen += 1
+			}
+		}
+
+		expected := NewEIA3(key, uint32(i), 0x0a, zuc.KEY_DOWNLINK).Hash(concat, blen)
+		mac, err := NewEIA3(key, uint32(i), 0x0a, zuc.KEY_DOWNLINK).HashSegments(segs)
+
+		assert.Nil(t, err)
+		assert.Equal(t, expected, mac, "MAC mismatched for %d bits in %d segments!", blen, len(segs))
+	}
+
+	for i, ts := range macVectors() {
+		t.Run(fmt.Sprintf("Test Set %d", i+1), func(t *testing.T) {
+			key, msg, mac := ts.Key, ts.Message, ts.MAC
+
+			split := ts.BitLength / 3
+			segs := []BitSegment{
+				{Data: msg, Offset: 0, Length: split},
+				{Data: msg, Offset: split, Length: ts.BitLength - split},
+			}
+
+			result, err := NewEIA3(key, ts.Count, ts.Bearer, ts.Direction).HashSegments(segs)
+			assert.Nil(t, err)
+			assert.Equal(t, mac, result, "MAC mismatched!")
+		})
+	}
+
+	_, err := NewEIA3(key, 0, 0, zuc.KEY_UPLINK).HashSegments([]BitSegment{{Data: make([]byte, 2), Offset: 9, Length: 8}})
+	assert.Equal(t, ErrSegmentRange, err)
+}
+
+func TestVerifyBatch(t *testing.T) {
+	items := macVectors()
+	valid := len(items)
+
+	tampered := items[0]
+	tampered.Count += 1
+
+	items = append(items,
+		tampered,
+		VerifyItem{Key: make([]byte, 8), Message: make([]byte, 4), BitLength: 32, MAC: make([]byte, 4)},
+		VerifyItem{Key: make([]byte, 16), Message: make([]byte, 4), BitLength: 33, MAC: make([]byte, 4)},
+		VerifyItem{Key: make([]byte, 16), Message: make([]byte, 4), BitLength: 32, MAC: make([]byte, 2)},
+	)
+
+	errs := VerifyBatch(items)
+	assert.Equal(t, len(items), len(errs))
+
+	for i := 0; i < valid; i += 1 {
+		assert.Nil(t, errs[i], "Test Set %d", i+1)
+	}
+
+	assert.Equal(t, ErrMACMismatch, errs[valid])
+	assert.Equal(t, ErrKeySize, errs[valid+1])
+	assert.Equal(t, ErrMessageTooShort, errs[valid+2])
+	assert.Equal(t, ErrMACLength, errs[valid+3])
+
+	assert.Equal(t, 0, len(VerifyBatch(nil)))
+}
+
+func TestHash64(t *testing.T) {
+	r := rand.New(rand.NewSource(0x6464))
+	key := make([]byte, 16)
+	msg := make([]byte, 5000)
+	r.Read(key)
+	r.Read(msg)
+
+	ks := zuc.NewZUC(key, msg[:16]).GenerateKeystream((5000*8 + 64 + 31) / 32)
+
+	for _, blen := range []uint32{0, 1, 8191, 8192, 8193, 8192 + 31, 8192 + 32, 16384, 5000 * 8} {
+		expected := make([]byte, 4)
+		keylength := (blen + 64 + 31) / 32
+		binary.BigEndian.PutUint32(expected, hashBits(ks, msg, blen)^getZi(ks, blen)^ks[keylength-1])
+
+		eia3 := &EIA3{zuc: zuc.NewZUC(key, msg[:16])}
+		mac, err := eia3.Hash64(msg, uint64(blen))
+		assert.Nil(t, err)
+		assert.Equal(t, expected, mac, "MAC mismatched for %d bits!", blen)
+	}
+
+	assert.Nil(t, ValidateLength(msg, 5000*8))
+	assert.Equal(t, ErrMessageTooShort, ValidateLength(msg, 5000*8+1))
+	assert.Equal(t, ErrMessageTooLong, ValidateLength(msg, MaxLength+1))
+
+	large := make([]byte, MaxLength/8+1)
+	assert.Nil(t, ValidateLength(large, MaxLength))
+	assert.Equal(t, ErrMessageTooLong, ValidateLength(large, MaxLength+1))
+
+	for blen, expected := range map[uint64]error{5000*8 + 1: ErrMessageTooShort, MaxLength + 1: ErrMessageTooLong, 1 << 40: ErrMessageTooLong} {
+		_, err := NewEIA3(key, 0, 0, zuc.KEY_UPLINK).Hash64(msg, blen)
+		assert.Equal(t, expected, err, "Hash64 with %d bits.", blen)
+	}
+
+	_, err := NewEIA3(key, 0, 0, zuc.KEY_UPLINK).HashSegments([]BitSegment{{Data: large, Length: MaxLength}, {Data: msg, Length: 1}})
+	assert.Equal(t, ErrMessageTooLong, err)
+
+	assert.Panics(t, func() { NewEIA3(key, 0, 0, zuc.KEY_UPLINK).Hash(msg, 0xffffffff) })
+
+	// Hash and VerifyMAC agree beyond MaxLength, both leave it to the caller.
+	mac := NewEIA3(key, 0, 0, zuc.KEY_UPLINK).Hash(large, MaxLength+1)
+	assert.Nil(t, NewEIA3(key, 0, 0, zuc.KEY_UPLINK).VerifyMAC(large, MaxLength+1, mac))
+}
+
+func TestRegistry(t *testing.T) {
+	ts := macVectors()[1]
+
+	integrity, err := algorithm.NewIntegrity(algorithm.EIA3, ts.Key, ts.Count, ts.Bearer, ts.Direction)
+	assert.Nil(t, err)
+	assert.Equal(t, ts.MAC, integrity.Hash(ts.Message, ts.BitLength))
+
+	_, err = algorithm.NewIntegrity(algorithm.NIA3, ts.Key[:8], ts.Count, ts.Bearer, ts.Direction)
+	assert.Equal(t, ErrKeySize, err)
+}
@@ -0,0 +1,50 @@
+//go:build amd64 && !purego
+// +build amd64,!purego
+
+package eia3
+
+import (
+	"golang.org/x/sys/cpu"
+	"math/bits"
+)
+
+const clmulChunk = 64
+
+var useCLMUL = cpu.X86.HasPCLMULQDQ
+
+// macBlocksCLMUL returns the XOR over the words of m of bits 31..62 of the carry-less product of
+// the big-endian message word by rks[i+1] || rks[i], where rks holds the bit-reversed keystream.
+// len(rks) must be at least len(m)/4 + 1.
+//
+//go:noescape
+func macBlocksCLMUL(m []byte, rks []uint32) uint32
+
+// macBlocks folds the first words message words into the MAC.
+//
+// With k = z_{32i} || z_{32i+32}, the contribution of message word w is the high half of the low
+// 64 bits of clmul(k, rev(w)). Reversing both operands instead gives clmul(rev(k), w), whose bits
+// 31..62 hold the contribution bit-reversed, so only the keystream needs reversing per word and the
+// accumulated result is reversed once at the end.
+func macBlocks(ks []uint32, m []byte, words uint32) uint32 {
+	if !useCLMUL {
+		return macBlocksGeneric(ks, m, words)
+	}
+
+	var rks [clmulChunk + 1]uint32
+
+	acc := uint32(0)
+	for i := uint32(0); i < words; i += clmulChunk {
+		n := words - i
+		if n > clmulChunk {
+			n = clmulChunk
+		}
+
+		for j := uint32(0); j <= n; j += 1 {
+			rks[j] = bits.Reverse32(ks[i+j])
+		}
+
+		acc ^= macBlocksCLMUL(m[4*i:4*(i+n)], rks[:n+1])
+	}
+
+	return bits.Reverse32(acc)
+}
@@ -0,0 +1,36 @@
+//go:build amd64 && !purego
+// +build amd64,!purego
+
+#include "textflag.h"
+
+// func macBlocksCLMUL(m []byte, rks []uint32) uint32
+TEXT ·macBlocksCLMUL(SB), NOSPLIT, $0-52
+	MOVQ m_base+0(FP), SI
+	MOVQ m_len+8(FP), CX
+	MOVQ rks_base+24(FP), DI
+	SHRQ $2, CX
+	XORL BX, BX
+
+loop:
+	TESTQ CX, CX
+	JZ    done
+
+	// X0 = rks[i+1] || rks[i], X1 = big-endian message word
+	MOVQ    (DI), X0
+	MOVL    (SI), AX
+	BSWAPL  AX
+	MOVQ    AX, X1
+	PCLMULQDQ $0x00, X1, X0
+
+	MOVQ X0, DX
+	SHRQ $31, DX
+	XORL DX, BX
+
+	ADDQ $4, SI
+	ADDQ $4, DI
+	DECQ CX
+	JMP  loop
+
+done:
+	MOVL BX, ret+48(FP)
+	RET
@@ -0,0 +1,8 @@
+//go:build !amd64 || purego
+// +build !amd64 purego
+
+package eia3
+
+func macBlocks(ks []uint32, m []byte, words uint32) uint32 {
+	return macBlocksGeneric(ks, m, words)
+}
@@ -0,0 +1,20 @@
+package eia3
+
+import (
+	"github.com/frankurcrazy/zuc"
+	"github.com/frankurcrazy/zuc/algorithm"
+)
+
+func init() {
+	err := algorithm.RegisterIntegrity(algorithm.EIA3, func(ik []byte, count uint32, bearer uint32, direction zuc.KeyDirection) (algorithm.Integrity, error) {
+		if len(ik) != 16 {
+			return nil, ErrKeySize
+		}
+
+		return NewEIA3(ik, count, bearer, direction), nil
+	})
+
+	if err != nil {
+		panic(err)
+	}
+}
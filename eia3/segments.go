@@ -0,0 +1,110 @@
+package eia3
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+var ErrSegmentRange = errors.New("eia3: segment exceeds its buffer")
+
+// BitSegment is Length bits of Data starting at bit Offset, counted from the most significant bit of Data[0].
+type BitSegment struct {
+	Data   []byte
+	Offset uint32
+	Length uint32
+}
+
+// loadBits returns n <= 32 bits of data starting at bit offset, aligned to the most significant bit.
+func loadBits(data []byte, offset uint32, n uint32) uint32 {
+	shift := offset % 8
+	nbytes := (shift + n + 7) / 8
+
+	v := uint64(0)
+	for i := uint32(0); i < nbytes; i += 1 {
+		v |= uint64(data[offset/8+i]) << (56 - 8*i)
+	}
+
+	return uint32((v << shift) >> 32 & (^uint64(0) << (32 - n)))
+}
+
+// segmentPacker re-aligns bits gathered from several segments into whole message words.
+type segmentPacker struct {
+	ks   []uint32
+	t    uint32
+	idx  uint32
+	acc  uint64
+	nacc uint32
+}
+
+func (p *segmentPacker) push(w uint32, n uint32) {
+	p.acc |= (uint64(w) << 32) >> p.nacc
+	p.nacc += n
+
+	if p.nacc >= 32 {
+		p.t ^= macWord(uint32(p.acc>>32), uint64(p.ks[p.idx])<<32|uint64(p.ks[p.idx+1]))
+		p.idx += 1
+		p.acc <<= 32
+		p.nacc -= 32
+	}
+}
+
+func (p *segmentPacker) write(seg BitSegment) {
+	offset, remaining := seg.Offset, seg.Length
+
+	if p.nacc == 0 && offset%8 == 0 && remaining >= 32 {
+		words := remaining / 32
+		p.t ^= macBlocks(p.ks[p.idx:], seg.Data[offset/8:], words)
+		p.idx += words
+		offset += 32 * words
+		remaining -= 32 * words
+	}
+
+	for remaining > 0 {
+		n := remaining
+		if n > 32 {
+			n = 32
+		}
+
+		p.push(loadBits(seg.Data, offset, n), n)
+		offset += n
+		remaining -= n
+	}
+}
+
+func (p *segmentPacker) flush() {
+	if p.nacc > 0 {
+		p.t ^= macWord(uint32(p.acc>>32), uint64(p.ks[p.idx])<<32|uint64(p.ks[p.idx+1]))
+	}
+}
+
+// HashSegments computes the MAC of the concatenation of segs without copying them.
+func (e *EIA3) HashSegments(segs []BitSegment) ([]byte, error) {
+	blen := uint64(0)
+	for _, seg := range segs {
+		if uint64(seg.Offset)+uint64(seg.Length) > uint64(len(seg.Data))*8 {
+			return nil, ErrSegmentRange
+		}
+
+		blen += uint64(seg.Length)
+	}
+
+	if blen > MaxLength {
+		return nil, ErrMessageTooLong
+	}
+
+	keylength := uint32((blen + 64 + 31) / 32)
+	ks := e.zuc.GenerateKeystream(keylength)
+
+	p := &segmentPacker{ks: ks}
+	for _, seg := range segs {
+		p.write(seg)
+	}
+	p.flush()
+
+	t := p.t ^ getZi(ks, uint32(blen))
+
+	mac := make([]byte, 4)
+	binary.BigEndian.PutUint32(mac, t^ks[keylength-1])
+
+	return mac, nil
+}
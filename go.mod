@@ -2,4 +2,7 @@ module github.com/frankurcrazy/zuc
 
 go 1.13
 
-require github.com/stretchr/testify v1.5.1
+require (
+	github.com/stretchr/testify v1.5.1
+	golang.org/x/sys v0.0.0-20210330210617-4fbd30eecc44
+)
@@ -0,0 +1,144 @@
+// Handover key chaining of 3GPP TS 33.401 clause 7.2.8 (EPS) and TS 33.501 clause 6.9.2 (5GS).
+// A Chain holds the current KeNB/KgNB, the latest NH and its NCC, and derives the AS keys that
+// seed EEA3/EIA3 after each handover.
+
+package handover
+
+import (
+	"errors"
+	"github.com/frankurcrazy/zuc/kdf"
+)
+
+type System int
+
+const (
+	EPS = System(iota)
+	FiveGS
+)
+
+// NCC is a 3-bit counter.
+const nccModulus = 8
+
+var ErrNCC = errors.New("handover: NCC out of range")
+
+// ASKeys are the 128-bit access stratum keys for the selected algorithms.
+type ASKeys struct {
+	RRCenc []byte
+	RRCint []byte
+	UPenc  []byte
+	UPint  []byte
+}
+
+type Chain struct {
+	system System
+	root   []byte
+	key    []byte
+	keyNCC uint8
+	nh     []byte
+	nhNCC  uint8
+}
+
+// NewChain starts a chain from KASME (EPS) or KAMF (5GS) and the uplink NAS COUNT used to derive
+// the initial KeNB/KgNB. The initial key acts as the virtual NH of NCC 0. accessType is only used
+// for 5GS.
+func NewChain(system System, root []byte, uplinkNASCount uint32, accessType kdf.AccessType) *Chain {
+	c := &Chain{
+		system: system,
+		root:   append([]byte{}, root...),
+	}
+
+	if system == FiveGS {
+		c.key = kdf.KgNB(root, uplinkNASCount, accessType)
+	} else {
+		c.key = kdf.KeNB(root, uplinkNASCount)
+	}
+
+	c.nh = c.key
+
+	return c
+}
+
+// Key returns the current KeNB/KgNB.
+func (c *Chain) Key() []byte {
+	return c.key
+}
+
+// NCC returns the NCC associated with the current KeNB/KgNB.
+func (c *Chain) NCC() uint8 {
+	return c.keyNCC
+}
+
+// NH returns the latest NH and its NCC.
+func (c *Chain) NH() ([]byte, uint8) {
+	return c.nh, c.nhNCC
+}
+
+// NextNH computes the next NH from the root key, as the MME/AMF does on each path switch.
+func (c *Chain) NextNH() ([]byte, uint8) {
+	if c.system == FiveGS {
+		c.nh = kdf.NH5G(c.root, c.nh)
+	} else {
+		c.nh = kdf.NH(c.root, c.nh)
+	}
+
+	c.nhNCC = (c.nhNCC + 1) % nccModulus
+
+	return c.NH()
+}
+
+func (c *Chain) star(key []byte, pci uint16, arfcnDL uint32) []byte {
+	if c.system == FiveGS {
+		return kdf.KgNBStar(key, pci, arfcnDL)
+	}
+
+	return kdf.KeNBStar(key, pci, arfcnDL)
+}
+
+// Horizontal derives KeNB*/KgNB* from the current key for the target cell and makes it current.
+func (c *Chain) Horizontal(pci uint16, arfcnDL uint32) []byte {
+	c.key = c.star(c.key, pci, arfcnDL)
+
+	return c.key
+}
+
+// Vertical derives KeNB*/KgNB* from the latest NH for the target cell and makes it current.
+func (c *Chain) Vertical(pci uint16, arfcnDL uint32) []byte {
+	c.key = c.star(c.nh, pci, arfcnDL)
+	c.keyNCC = c.nhNCC
+
+	return c.key
+}
+
+// Handover derives the key for the target cell as a UE does from the NCC received in the
+// handover command: horizontally if ncc matches the current key, otherwise vertically from the
+// NH of that NCC, computing NHs forward as needed.
+func (c *Chain) Handover(ncc uint8, pci uint16, arfcnDL uint32) ([]byte, error) {
+	if ncc >= nccModulus {
+		return nil, ErrNCC
+	}
+
+	if ncc == c.keyNCC {
+		return c.Horizontal(pci, arfcnDL), nil
+	}
+
+	for c.nhNCC != ncc {
+		c.NextNH()
+	}
+
+	return c.Vertical(pci, arfcnDL), nil
+}
+
+// Keys derives the AS keys of the current KeNB/KgNB for the selected ciphering and integrity algorithms.
+func (c *Chain) Keys(encAlg uint8, intAlg uint8) ASKeys {
+	derive := kdf.AlgorithmKey
+	if c.system == FiveGS {
+		derive = kdf.NRAlgorithmKey
+	}
+
+	return ASKeys{
+		RRCenc: derive(c.key, kdf.RRCEnc, encAlg),
+		RRCint: derive(c.key, kdf.RRCInt, intAlg),
+		UPenc:  derive(c.key, kdf.UPEnc, encAlg),
+		UPint:  derive(c.key, kdf.UPInt, intAlg),
+	}
+}
@@ -0,0 +1,94 @@
+package handover
+
+import (
+	"encoding/hex"
+	"github.com/frankurcrazy/zuc"
+	"github.com/frankurcrazy/zuc/eea3"
+	"github.com/frankurcrazy/zuc/kdf"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func decode(s string) []byte {
+	b, _ := hex.DecodeString(s)
+
+	return b
+}
+
+// Expected values were computed with an independent HMAC-SHA-256 implementation following
+// TS 33.401 Annex A.3, A.4, A.5, A.7 and TS 33.501 Annex A.8, A.9, A.10, A.11.
+var root = decode("238e457e0f758badbca8d34bb2612c10428d426757cb5553b2b184fa64bfc549")
+
+func TestChainEPS(t *testing.T) {
+	c := NewChain(EPS, root, 5, kdf.Access3GPP)
+	assert.Equal(t, decode("4a377799fffb14d01fb363ea92ce1602aa2f3569aeccbaff0e20a78b809c6e70"), c.Key(), "KeNB mismatched!")
+	assert.Equal(t, uint8(0), c.NCC())
+
+	key, err := c.Handover(0, 0x1f3, 1850)
+	assert.Nil(t, err)
+	assert.Equal(t, decode("b98571a11992c03e7e31b554faf8153d6efc0f3d57d7cce10bdf9e02ad82861d"), key, "Horizontal KeNB* mismatched!")
+	assert.Equal(t, uint8(0), c.NCC())
+
+	key, err = c.Handover(2, 0x0a, 70000)
+	assert.Nil(t, err)
+	assert.Equal(t, decode("137fbf651eaa37efc13682bc07b0ccca635700b137f7f863bbcae895312cd13b"), key, "Vertical KeNB* mismatched!")
+	assert.Equal(t, uint8(2), c.NCC())
+
+	nh, ncc := c.NH()
+	assert.Equal(t, decode("4051741272edcd3ffca67e133e92b2ffadbfb765750831a9a42ae6f31d91e299"), nh, "NH mismatched!")
+	assert.Equal(t, uint8(2), ncc)
+
+	keys := c.Keys(kdf.AlgorithmEEA3, kdf.AlgorithmEIA3)
+	assert.Equal(t, decode("d087270ec741de902440ad554e7d28e6"), keys.RRCenc)
+	assert.Equal(t, decode("de75267cc85d0eac5da37db5dcc91c51"), keys.RRCint)
+	assert.Equal(t, decode("0bb8371a1d36b6af828d26dee8f9b854"), keys.UPenc)
+	assert.Equal(t, decode("bc66d4502e9e1c56c821767abba3800b"), keys.UPint)
+
+	pdu := decode("6cf65340735552ab0c9752fa6f9025fe")
+	ciphertext := eea3.NewEEA3(keys.UPenc, 1, 0, zuc.KEY_DOWNLINK).Encrypt(pdu, 128)
+	assert.Equal(t, pdu, eea3.NewEEA3(keys.UPenc, 1, 0, zuc.KEY_DOWNLINK).Decrypt(ciphertext, 128))
+
+	_, err = c.Handover(8, 0x0a, 70000)
+	assert.Equal(t, ErrNCC, err)
+}
+
+func TestChain5GS(t *testing.T) {
+	c := NewChain(FiveGS, root, 5, kdf.Access3GPP)
+	assert.Equal(t, decode("41830c56c8deb9a3bb3bd15a2bd15bf2ed901c34ce17d86e2572118ae78eb8c3"), c.Key(), "KgNB mismatched!")
+
+	key := c.Horizontal(0x1f3, 632628)
+	assert.Equal(t, decode("83a7b69fab77c16f2dd1f80c504a005312bc06748aaa1ef3d301a98cbfb3bf7f"), key, "Horizontal KgNB* mismatched!")
+
+	nh, ncc := c.NextNH()
+	assert.Equal(t, decode("454a3f9d9dd383595aa2c09f335a6c53a132acea84e73e3528417b89dc053b2b"), nh, "NH mismatched!")
+	assert.Equal(t, uint8(1), ncc)
+
+	key = c.Vertical(0x0a, 632628)
+	assert.Equal(t, decode("99a3a31564f633e416374993a605ec87a7dad88b572325fa009a51dcda342e79"), key, "Vertical KgNB* mismatched!")
+	assert.Equal(t, uint8(1), c.NCC())
+
+	keys := c.Keys(kdf.AlgorithmNEA3, kdf.AlgorithmNIA3)
+	assert.Equal(t, decode("0fc3816e89b720003957ebd82412b258"), keys.RRCenc)
+	assert.Equal(t, decode("03a26b825d0e98a15f1d7bc9cda7a881"), keys.RRCint)
+	assert.Equal(t, decode("12ac2d192236e1b3cf7a4e8e0b332c19"), keys.UPenc)
+	assert.Equal(t, decode("c0b1134aa9a87c44d562a3a8445f8291"), keys.UPint)
+}
+
+func TestNCCWraparound(t *testing.T) {
+	network := NewChain(EPS, root, 0, kdf.Access3GPP)
+	ue := NewChain(EPS, root, 0, kdf.Access3GPP)
+
+	for i := 0; i < 20; i += 1 {
+		_, ncc := network.NextNH()
+		if i%3 == 0 {
+			continue
+		}
+
+		expected := network.Vertical(uint16(i), 1850)
+		key, err := ue.Handover(ncc, uint16(i), 1850)
+
+		assert.Nil(t, err)
+		assert.Equal(t, expected, key, "Key mismatched after %d NH steps!", i+1)
+		assert.True(t, ncc < 8)
+	}
+}
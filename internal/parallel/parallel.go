@@ -0,0 +1,43 @@
+// Bounded worker fan-out shared by the batch APIs of eea3 and eia3.
+
+package parallel
+
+import (
+	"runtime"
+	"sync"
+)
+
+// For calls the work function for every index in [0, n) on up to workers goroutines and returns once all of
+// them finished. A non-positive workers uses GOMAXPROCS goroutines. newWorker is called once per goroutine,
+// so the work function it returns can keep state across the indexes it handles.
+func For(n int, workers int, newWorker func() func(i int)) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	if workers > n {
+		workers = n
+	}
+
+	next := make(chan int, n)
+	for i := 0; i < n; i += 1 {
+		next <- i
+	}
+	close(next)
+
+	wg := sync.WaitGroup{}
+	wg.Add(workers)
+
+	for w := 0; w < workers; w += 1 {
+		go func() {
+			defer wg.Done()
+
+			work := newWorker()
+			for i := range next {
+				work(i)
+			}
+		}()
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,34 @@
+package parallel
+
+import (
+	"github.com/stretchr/testify/assert"
+	"sync/atomic"
+	"testing"
+)
+
+func TestFor(t *testing.T) {
+	for _, workers := range []int{-1, 0, 1, 3, 64} {
+		seen := make([]int32, 10)
+		started := int32(0)
+
+		For(len(seen), workers, func() func(int) {
+			atomic.AddInt32(&started, 1)
+			return func(i int) {
+				atomic.AddInt32(&seen[i], 1)
+			}
+		})
+
+		for i := range seen {
+			assert.Equal(t, int32(1), seen[i], "index %d with %d workers", i, workers)
+		}
+
+		if workers > 0 {
+			assert.True(t, started <= int32(workers))
+		}
+	}
+
+	For(0, 4, func() func(int) {
+		t.Fatal("no worker should start for an empty batch")
+		return nil
+	})
+}
@@ -0,0 +1,120 @@
+// Code adapted from 3GPP TS 35.202, Specification of the 3GPP Confidentiality and Integrity
+// Algorithms; Document 2: KASUMI Specification.
+
+package kasumi
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	KeySize   = 16
+	BlockSize = 8
+)
+
+var ErrKeySize = errors.New("kasumi: key must be 16 bytes")
+
+// c are the constants K'j = Kj ⊕ Cj of the key schedule, TS 35.202 section 4.4.
+var c = [8]uint16{0x0123, 0x4567, 0x89ab, 0xcdef, 0xfedc, 0xba98, 0x7654, 0x3210}
+
+type KASUMI struct {
+	kl1, kl2      [8]uint16
+	ko1, ko2, ko3 [8]uint16
+	ki1, ki2, ki3 [8]uint16
+}
+
+func rol16(a uint16, b uint) uint16 {
+	return a<<b | a>>(16-b)
+}
+
+// NewKASUMI runs the key schedule for a 16-byte key.
+func NewKASUMI(k []byte) (*KASUMI, error) {
+	if len(k) != KeySize {
+		return nil, ErrKeySize
+	}
+
+	key := [8]uint16{}
+	kprime := [8]uint16{}
+	for i := range key {
+		key[i] = binary.BigEndian.Uint16(k[2*i:])
+		kprime[i] = key[i] ^ c[i]
+	}
+
+	s := &KASUMI{}
+	for i := 0; i < 8; i += 1 {
+		s.kl1[i] = rol16(key[i], 1)
+		s.kl2[i] = kprime[(i+2)&7]
+		s.ko1[i] = rol16(key[(i+1)&7], 5)
+		s.ko2[i] = rol16(key[(i+5)&7], 8)
+		s.ko3[i] = rol16(key[(i+6)&7], 13)
+		s.ki1[i] = kprime[(i+4)&7]
+		s.ki2[i] = kprime[(i+3)&7]
+		s.ki3[i] = kprime[(i+7)&7]
+	}
+
+	return s, nil
+}
+
+func fi(in uint16, ki uint16) uint16 {
+	nine := in >> 7
+	seven := in & 0x7f
+
+	nine = S9[nine] ^ seven
+	seven = S7[seven] ^ (nine & 0x7f)
+
+	seven ^= ki >> 9
+	nine ^= ki & 0x1ff
+
+	nine = S9[nine] ^ seven
+	seven = S7[seven] ^ (nine & 0x7f)
+
+	return seven<<9 | nine
+}
+
+func (s *KASUMI) fo(in uint32, i int) uint32 {
+	left := uint16(in >> 16)
+	right := uint16(in)
+
+	left ^= s.ko1[i]
+	left = fi(left, s.ki1[i])
+	left ^= right
+
+	right ^= s.ko2[i]
+	right = fi(right, s.ki2[i])
+	right ^= left
+
+	left ^= s.ko3[i]
+	left = fi(left, s.ki3[i])
+	left ^= right
+
+	return uint32(right)<<16 | uint32(left)
+}
+
+func (s *KASUMI) fl(in uint32, i int) uint32 {
+	l := uint16(in >> 16)
+	r := uint16(in)
+
+	r ^= rol16(l&s.kl1[i], 1)
+	l ^= rol16(r|s.kl2[i], 1)
+
+	return uint32(l)<<16 | uint32(r)
+}
+
+// EncryptBlock enciphers one 64-bit block.
+func (s *KASUMI) EncryptBlock(block uint64) uint64 {
+	left := uint32(block >> 32)
+	right := uint32(block)
+
+	for i := 0; i < 8; i += 2 {
+		right ^= s.fo(s.fl(left, i), i)
+		left ^= s.fl(s.fo(right, i+1), i+1)
+	}
+
+	return uint64(left)<<32 | uint64(right)
+}
+
+// Encrypt enciphers the first 8 bytes of src into dst.
+func (s *KASUMI) Encrypt(dst []byte, src []byte) {
+	binary.BigEndian.PutUint64(dst, s.EncryptBlock(binary.BigEndian.Uint64(src)))
+}
@@ -0,0 +1,67 @@
+package kasumi
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestKASUMI(t *testing.T) {
+	type TestSet struct {
+		Key        string
+		Plaintext  string
+		Ciphertext string
+	}
+
+	testSets := map[string]TestSet{
+		"Test Set 1": TestSet{
+			Key:        "2bd6459f82c5b300952c49104881ff48",
+			Plaintext:  "ea024714ad5c4d84",
+			Ciphertext: "df1f9b251c0bf45f",
+		},
+		"Test Set 2": TestSet{
+			Key:        "8ce33e2cc3c0b5fc1f3de8a6dc66b1f3",
+			Plaintext:  "d3c5d592327fb11c",
+			Ciphertext: "de551988ceb2f9b7",
+		},
+		"Test Set 3": TestSet{
+			Key:        "4035c6680af8c6d1a8ff8667b1714013",
+			Plaintext:  "62a540981ba6f9b7",
+			Ciphertext: "4592b0e78690f71b",
+		},
+	}
+
+	for n, ts := range testSets {
+		t.Run(n, func(t *testing.T) {
+			key, _ := hex.DecodeString(ts.Key)
+			plaintext, _ := hex.DecodeString(ts.Plaintext)
+			ciphertext, _ := hex.DecodeString(ts.Ciphertext)
+
+			k, err := NewKASUMI(key)
+			assert.Nil(t, err)
+
+			out := make([]byte, BlockSize)
+			k.Encrypt(out, plaintext)
+			assert.Equal(t, ciphertext, out)
+			assert.Equal(t, binary.BigEndian.Uint64(ciphertext), k.EncryptBlock(binary.BigEndian.Uint64(plaintext)))
+		})
+	}
+
+	_, err := NewKASUMI(make([]byte, 8))
+	assert.Equal(t, ErrKeySize, err)
+}
+
+func TestSBoxes(t *testing.T) {
+	seen7 := map[uint16]bool{}
+	for _, v := range S7 {
+		seen7[v] = true
+	}
+	assert.Equal(t, 128, len(seen7))
+
+	seen9 := map[uint16]bool{}
+	for _, v := range S9 {
+		seen9[v] = true
+	}
+	assert.Equal(t, 512, len(seen9))
+}
@@ -0,0 +1,108 @@
+// 5GS key derivations of 3GPP TS 33.501 Annex A.
+
+package kdf
+
+import (
+	"fmt"
+	"strings"
+)
+
+type AccessType uint8
+
+// Access type distinguishers, TS 33.501 Table A.9-1.
+const (
+	Access3GPP    = AccessType(0x01)
+	AccessNon3GPP = AccessType(0x02)
+)
+
+// Algorithm identities of 128-NEA3 and 128-NIA3, TS 33.501 Annex D.
+const (
+	AlgorithmNEA3 = uint8(3)
+	AlgorithmNIA3 = uint8(3)
+)
+
+const (
+	FCKAUSF          = uint8(0x6a)
+	FCKSEAF          = uint8(0x6c)
+	FCKAMF           = uint8(0x6d)
+	FCKgNB           = uint8(0x6e)
+	FCNRAlgorithmKey = uint8(0x69)
+	FCNH5G           = uint8(0x6f)
+	FCKgNBStar       = uint8(0x70)
+)
+
+// ServingNetworkName builds the serving network name of TS 24.501 clause 9.12.1, padding the MNC to three digits.
+func ServingNetworkName(mcc string, mnc string) string {
+	if len(mnc) == 2 {
+		mnc = "0" + mnc
+	}
+
+	return fmt.Sprintf("5G:mnc%s.mcc%s.3gppnetwork.org", mnc, mcc)
+}
+
+// KAUSF derives KAUSF from CK || IK for 5G AKA, TS 33.501 Annex A.2.
+func KAUSF(ck []byte, ik []byte, servingNetworkName string, sqnXorAK []byte) []byte {
+	key := make([]byte, 0, len(ck)+len(ik))
+	key = append(key, ck...)
+	key = append(key, ik...)
+
+	return KDF(key, FCKAUSF, []byte(servingNetworkName), sqnXorAK)
+}
+
+// KSEAF derives KSEAF from KAUSF, TS 33.501 Annex A.6.
+func KSEAF(kausf []byte, servingNetworkName string) []byte {
+	return KDF(kausf, FCKSEAF, []byte(servingNetworkName))
+}
+
+// KAMF derives KAMF from KSEAF, TS 33.501 Annex A.7. An IMSI based SUPI is given either as the
+// digits alone or with its "imsi-" prefix, which is stripped.
+func KAMF(kseaf []byte, supi string, abba []byte) []byte {
+	supi = strings.TrimPrefix(supi, "imsi-")
+
+	return KDF(kseaf, FCKAMF, []byte(supi), abba)
+}
+
+// KgNB derives KgNB (or KN3IWF) from KAMF, TS 33.501 Annex A.9.
+func KgNB(kamf []byte, uplinkNASCount uint32, accessType AccessType) []byte {
+	return KDF(kamf, FCKgNB, encodeUint(uplinkNASCount, 4), []byte{uint8(accessType)})
+}
+
+// NH5G derives the next hop parameter from KAMF, TS 33.501 Annex A.10.
+func NH5G(kamf []byte, syncInput []byte) []byte {
+	return KDF(kamf, FCNH5G, syncInput)
+}
+
+// KgNBStar derives KNG-RAN* for the target PCI and ARFCN-DL, TS 33.501 Annex A.11.
+func KgNBStar(key []byte, pci uint16, arfcnDL uint32) []byte {
+	return KDF(key, FCKgNBStar, encodeUint(uint32(pci), 2), encodeUint(arfcnDL, 3))
+}
+
+// NRAlgorithmKey derives a 128-bit algorithm key from KAMF (NAS keys) or KgNB (AS keys), TS 33.501 Annex A.8.
+// The algorithm type distinguishers have the same values as in EPS.
+func NRAlgorithmKey(key []byte, algType AlgorithmType, algID uint8) []byte {
+	return Truncate(KDF(key, FCNRAlgorithmKey, []byte{uint8(algType)}, []byte{algID}))
+}
+
+func KNASenc5G(kamf []byte, algID uint8) []byte {
+	return NRAlgorithmKey(kamf, NASEnc, algID)
+}
+
+func KNASint5G(kamf []byte, algID uint8) []byte {
+	return NRAlgorithmKey(kamf, NASInt, algID)
+}
+
+func KRRCenc5G(kgnb []byte, algID uint8) []byte {
+	return NRAlgorithmKey(kgnb, RRCEnc, algID)
+}
+
+func KRRCint5G(kgnb []byte, algID uint8) []byte {
+	return NRAlgorithmKey(kgnb, RRCInt, algID)
+}
+
+func KUPenc5G(kgnb []byte, algID uint8) []byte {
+	return NRAlgorithmKey(kgnb, UPEnc, algID)
+}
+
+func KUPint5G(kgnb []byte, algID uint8) []byte {
+	return NRAlgorithmKey(kgnb, UPInt, algID)
+}
@@ -0,0 +1,158 @@
+// Key derivation function of 3GPP TS 33.220 Annex B.2 and the EPS key derivations of TS 33.401 Annex A.
+
+package kdf
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+type AlgorithmType uint8
+
+// Algorithm type distinguishers, TS 33.401 Table A.7-1.
+const (
+	NASEnc = AlgorithmType(0x01)
+	NASInt = AlgorithmType(0x02)
+	RRCEnc = AlgorithmType(0x03)
+	RRCInt = AlgorithmType(0x04)
+	UPEnc  = AlgorithmType(0x05)
+	UPInt  = AlgorithmType(0x06)
+)
+
+// Algorithm identities of 128-EEA3 and 128-EIA3, TS 33.401 Annex B.
+const (
+	AlgorithmEEA3 = uint8(3)
+	AlgorithmEIA3 = uint8(3)
+)
+
+const (
+	FCAlgorithmKey = uint8(0x15)
+
+	// KeySize is the size of the keys taken by eea3.NewEEA3 and eia3.NewEIA3.
+	KeySize = 16
+)
+
+// Encode builds the input string S = FC || P0 || L0 || P1 || L1 || ... where each Li is the
+// length of Pi in octets as a 2-octet big-endian integer.
+func Encode(fc uint8, params ...[]byte) []byte {
+	size := 1
+	for _, p := range params {
+		size += len(p) + 2
+	}
+
+	s := make([]byte, 0, size)
+	s = append(s, fc)
+	for _, p := range params {
+		s = append(s, p...)
+		s = append(s, uint8(len(p)>>8), uint8(len(p)))
+	}
+
+	return s
+}
+
+// KDF returns HMAC-SHA-256(key, S) with S encoded from fc and params.
+func KDF(key []byte, fc uint8, params ...[]byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(Encode(fc, params...))
+
+	return mac.Sum(nil)
+}
+
+// Truncate returns the 128 least significant bits of a 256-bit KDF output.
+func Truncate(key []byte) []byte {
+	return key[len(key)-KeySize:]
+}
+
+// AlgorithmKey derives a 128-bit algorithm key from KASME (NAS keys) or KeNB (AS keys), TS 33.401 Annex A.7.
+func AlgorithmKey(key []byte, algType AlgorithmType, algID uint8) []byte {
+	return Truncate(KDF(key, FCAlgorithmKey, []byte{uint8(algType)}, []byte{algID}))
+}
+
+func KNASenc(kasme []byte, algID uint8) []byte {
+	return AlgorithmKey(kasme, NASEnc, algID)
+}
+
+func KNASint(kasme []byte, algID uint8) []byte {
+	return AlgorithmKey(kasme, NASInt, algID)
+}
+
+func KRRCenc(kenb []byte, algID uint8) []byte {
+	return AlgorithmKey(kenb, RRCEnc, algID)
+}
+
+func KRRCint(kenb []byte, algID uint8) []byte {
+	return AlgorithmKey(kenb, RRCInt, algID)
+}
+
+func KUPenc(kenb []byte, algID uint8) []byte {
+	return AlgorithmKey(kenb, UPEnc, algID)
+}
+
+func KUPint(kenb []byte, algID uint8) []byte {
+	return AlgorithmKey(kenb, UPInt, algID)
+}
+
+const (
+	FCKASME    = uint8(0x10)
+	FCKeNB     = uint8(0x11)
+	FCNH       = uint8(0x12)
+	FCKeNBStar = uint8(0x13)
+)
+
+// ServingNetworkID encodes the PLMN identity of the serving network as in TS 24.301 clause 9.9.3.32,
+// with filler 0xf for a two digit MNC.
+func ServingNetworkID(mcc string, mnc string) []byte {
+	digit := func(s string, i int) uint8 {
+		if i >= len(s) {
+			return 0xf
+		}
+
+		return s[i] - '0'
+	}
+
+	return []byte{
+		digit(mcc, 1)<<4 | digit(mcc, 0),
+		digit(mnc, 2)<<4 | digit(mcc, 2),
+		digit(mnc, 1)<<4 | digit(mnc, 0),
+	}
+}
+
+// KASME derives KASME from CK || IK after EPS AKA, TS 33.401 Annex A.2.
+func KASME(ck []byte, ik []byte, servingNetworkID []byte, sqnXorAK []byte) []byte {
+	key := make([]byte, 0, len(ck)+len(ik))
+	key = append(key, ck...)
+	key = append(key, ik...)
+
+	return KDF(key, FCKASME, servingNetworkID, sqnXorAK)
+}
+
+// KeNB derives KeNB from KASME, TS 33.401 Annex A.3.
+func KeNB(kasme []byte, uplinkNASCount uint32) []byte {
+	return KDF(kasme, FCKeNB, encodeUint(uplinkNASCount, 4))
+}
+
+// NH derives the next hop parameter from KASME, TS 33.401 Annex A.4. syncInput is the initial
+// KeNB for the first NH and the previous NH afterwards.
+func NH(kasme []byte, syncInput []byte) []byte {
+	return KDF(kasme, FCNH, syncInput)
+}
+
+// KeNBStar derives KeNB* from the current KeNB (horizontal) or from NH (vertical) for the target
+// PCI and EARFCN-DL, TS 33.401 Annex A.5. EARFCN-DL above 65535 is encoded on three octets.
+func KeNBStar(key []byte, pci uint16, earfcnDL uint32) []byte {
+	earfcn := encodeUint(earfcnDL, 2)
+	if earfcnDL > 0xffff {
+		earfcn = encodeUint(earfcnDL, 3)
+	}
+
+	return KDF(key, FCKeNBStar, encodeUint(uint32(pci), 2), earfcn)
+}
+
+// encodeUint returns the n least significant octets of v, big-endian.
+func encodeUint(v uint32, n int) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, v)
+
+	return b[4-n:]
+}
@@ -0,0 +1,123 @@
+package kdf
+
+import (
+	"encoding/hex"
+	"github.com/frankurcrazy/zuc"
+	"github.com/frankurcrazy/zuc/eea3"
+	"github.com/frankurcrazy/zuc/eia3"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func decode(s string) []byte {
+	b, _ := hex.DecodeString(strings.Join(strings.Fields(s), ""))
+
+	return b
+}
+
+func TestEncode(t *testing.T) {
+	assert.Equal(t, decode("15"), Encode(0x15))
+	assert.Equal(t, decode("15 01 0001 03 0001"), Encode(0x15, []byte{0x01}, []byte{0x03}))
+	assert.Equal(t, decode("10 02f839 0003 aabbccddeeff 0006"), Encode(0x10, decode("02f839"), decode("aabbccddeeff")))
+	assert.Equal(t, decode("6a 0000"), Encode(0x6a, []byte{}))
+}
+
+func TestAlgorithmKey(t *testing.T) {
+	type TestSet struct {
+		Key      string
+		Derive   func(key []byte, algID uint8) []byte
+		Expected string
+	}
+
+	// KASME is derived from CK, IK and SQN ⊕ AK of TS 35.208 test set 1 for serving network 001/01,
+	// KeNB from that KASME with uplink NAS COUNT 0. TS 33.401 publishes no vectors for these derivations;
+	// expected values were computed with Python's hmac and hashlib modules from the encoding of
+	// TS 33.220 Annex B.2, S = 0x15 || algorithm type distinguisher || 0x0001 || 0x03 || 0x0001.
+	kasme := "48579af8781c742d5120e6ed8ccac13193f38c53ab7aa69396f49ca6e1b0562d"
+	kenb := "8214c68f2c779346814e4095c5b38cae9f5485c38006d711c0a379c0ec58796b"
+
+	testSets := map[string]TestSet{
+		"KNASenc": TestSet{Key: kasme, Derive: KNASenc, Expected: "8ad70d4ceaa9227d6e6d181d6e3a41a1"},
+		"KNASint": TestSet{Key: kasme, Derive: KNASint, Expected: "8654849376e7b6abb9b0f0435a4e28b6"},
+		"KRRCenc": TestSet{Key: kenb, Derive: KRRCenc, Expected: "6f4c43e68723f228a193a0bad708ec22"},
+		"KRRCint": TestSet{Key: kenb, Derive: KRRCint, Expected: "fcc36b49dfe859b75ed957e7fefec0d4"},
+		"KUPenc":  TestSet{Key: kenb, Derive: KUPenc, Expected: "9e3abdf0791d1ff8857f4fc40e083d55"},
+		"KUPint":  TestSet{Key: kenb, Derive: KUPint, Expected: "753d5420984d468fab5e1c47a44b0456"},
+	}
+
+	for n, ts := range testSets {
+		t.Run(n, func(t *testing.T) {
+			key := ts.Derive(decode(ts.Key), AlgorithmEEA3)
+
+			assert.Equal(t, KeySize, len(key))
+			assert.Equal(t, decode(ts.Expected), key, "Key mismatched!")
+		})
+	}
+
+	full := KDF(decode(kasme), FCAlgorithmKey, []byte{uint8(NASEnc)}, []byte{AlgorithmEEA3})
+	assert.Equal(t, decode("7d4dcb2047b02994cace95b8b643acd88ad70d4ceaa9227d6e6d181d6e3a41a1"), full)
+}
+
+func TestKeysWithEEA3EIA3(t *testing.T) {
+	kasme := decode("48579af8781c742d5120e6ed8ccac13193f38c53ab7aa69396f49ca6e1b0562d")
+	msg := decode("0741720bf602f8398000010000000102")
+
+	ciphertext := eea3.NewEEA3(KNASenc(kasme, AlgorithmEEA3), 1, 0, zuc.KEY_UPLINK).Encrypt(msg, uint32(len(msg)*8))
+	plaintext := eea3.NewEEA3(KNASenc(kasme, AlgorithmEEA3), 1, 0, zuc.KEY_UPLINK).Decrypt(ciphertext, uint32(len(msg)*8))
+	assert.Equal(t, msg, plaintext)
+
+	mac := eia3.NewEIA3(KNASint(kasme, AlgorithmEIA3), 1, 0, zuc.KEY_UPLINK).Hash(msg, uint32(len(msg)*8))
+	assert.True(t, eia3.NewEIA3(KNASint(kasme, AlgorithmEIA3), 1, 0, zuc.KEY_UPLINK).Verify(msg, uint32(len(msg)*8), mac))
+}
+
+func TestServingNetworkName(t *testing.T) {
+	assert.Equal(t, "5G:mnc093.mcc208.3gppnetwork.org", ServingNetworkName("208", "93"))
+	assert.Equal(t, "5G:mnc001.mcc001.3gppnetwork.org", ServingNetworkName("001", "001"))
+}
+
+func TestKeyHierarchy5G(t *testing.T) {
+	// CK, IK and SQN ⊕ AK of TS 35.208 test set 1, for SUPI imsi-208930000000001 on serving network 208/93.
+	// TS 33.501 publishes no vectors for these derivations; expected values were computed with Python's
+	// hmac and hashlib modules following TS 33.501 Annex A.2, A.6, A.7, A.8 and A.9.
+	ck := decode("b40ba9a3c58b2a05bbf0d987b21bf8cb")
+	ik := decode("f769bcd751044604127672711c6d3441")
+	snn := ServingNetworkName("208", "93")
+
+	kausf := KAUSF(ck, ik, snn, decode("55f328b43577"))
+	assert.Equal(t, decode("f2e35260f85194d4f891504d02111e56689ac23dd393bee3abbcc5bfbc013ef9"), kausf, "KAUSF mismatched!")
+
+	kseaf := KSEAF(kausf, snn)
+	assert.Equal(t, decode("cfddde483bd1318a412e98870f556410905be4fb7500abed93ee16af71bbb3fa"), kseaf, "KSEAF mismatched!")
+
+	kamf := KAMF(kseaf, "imsi-208930000000001", []byte{0x00, 0x00})
+	assert.Equal(t, decode("9d63b519775a92ca861ca6a50d848fa8ebf160ea7b73735a85b33737e73c55b4"), kamf, "KAMF mismatched!")
+	assert.Equal(t, kamf, KAMF(kseaf, "208930000000001", []byte{0x00, 0x00}))
+
+	kgnb := KgNB(kamf, 3, Access3GPP)
+	assert.Equal(t, decode("87ff3b11fe9a6cb83dd9261824704a0da62836dee385a8d74a255045ea9eb5d0"), kgnb, "KgNB mismatched!")
+
+	assert.Equal(t, decode("bc5654598911de56bf309e90fb6251f7"), KNASenc5G(kamf, AlgorithmNEA3), "KNASenc mismatched!")
+	assert.Equal(t, decode("0c22096fec5e9d9b374ecb6ff2d36a53"), KNASint5G(kamf, AlgorithmNIA3), "KNASint mismatched!")
+	assert.Equal(t, decode("d7bbc2dd008cedb8149b2f6f9622af79"), KRRCenc5G(kgnb, AlgorithmNEA3), "KRRCenc mismatched!")
+	assert.Equal(t, decode("4ca738a64b4aa49a2daceedd8cde0804"), KRRCint5G(kgnb, AlgorithmNIA3), "KRRCint mismatched!")
+	assert.Equal(t, decode("cc8c00da15c329cd8c22bde6531ecd50"), KUPenc5G(kgnb, AlgorithmNEA3), "KUPenc mismatched!")
+	assert.Equal(t, decode("63670dce1840efe144341ad52f0a0de5"), KUPint5G(kgnb, AlgorithmNIA3), "KUPint mismatched!")
+
+	assert.NotEqual(t, kgnb, KgNB(kamf, 3, AccessNon3GPP))
+}
+
+func TestServingNetworkID(t *testing.T) {
+	assert.Equal(t, decode("00f110"), ServingNetworkID("001", "01"))
+	assert.Equal(t, decode("02f839"), ServingNetworkID("208", "93"))
+	assert.Equal(t, decode("130062"), ServingNetworkID("310", "260"))
+}
+
+func TestKASME(t *testing.T) {
+	// CK, IK and SQN ⊕ AK of TS 35.208 test set 1; expected value computed with an independent
+	// HMAC-SHA-256 implementation.
+	kasme := KASME(decode("b40ba9a3c58b2a05bbf0d987b21bf8cb"), decode("f769bcd751044604127672711c6d3441"),
+		ServingNetworkID("001", "01"), decode("55f328b43577"))
+
+	assert.Equal(t, decode("48579af8781c742d5120e6ed8ccac13193f38c53ab7aa69396f49ca6e1b0562d"), kasme)
+}
@@ -0,0 +1,247 @@
+// Milenage, the example authentication and key generation functions f1, f1*, f2, f3, f4, f5 and
+// f5* of 3GPP TS 35.206.
+
+package milenage
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+)
+
+const (
+	KeySize  = 16
+	RANDSize = 16
+	SQNSize  = 6
+	AMFSize  = 2
+)
+
+var (
+	ErrKeySize  = errors.New("milenage: K, OP and OPc must be 16 bytes")
+	ErrRANDSize = errors.New("milenage: RAND must be 16 bytes")
+	ErrSQNSize  = errors.New("milenage: SQN must be 6 bytes")
+	ErrAMFSize  = errors.New("milenage: AMF must be 2 bytes")
+)
+
+// Milenage holds the subscriber key K and the operator variant OPc.
+type Milenage struct {
+	block cipher.Block
+	opc   [16]byte
+}
+
+// Vector is an authentication vector, with the SQN ⊕ AK that KASME and KAUSF are derived with.
+type Vector struct {
+	RAND     []byte
+	XRES     []byte
+	CK       []byte
+	IK       []byte
+	AK       []byte
+	SQNXorAK []byte
+	AUTN     []byte
+}
+
+// OPc computes OPc = OP ⊕ E_K(OP).
+func OPc(k []byte, op []byte) ([]byte, error) {
+	if len(k) != KeySize || len(op) != KeySize {
+		return nil, ErrKeySize
+	}
+
+	block, err := aes.NewCipher(k)
+	if err != nil {
+		return nil, err
+	}
+
+	opc := make([]byte, KeySize)
+	block.Encrypt(opc, op)
+	for i := range opc {
+		opc[i] ^= op[i]
+	}
+
+	return opc, nil
+}
+
+// New returns Milenage keyed with K and the operator variant OP.
+func New(k []byte, op []byte) (*Milenage, error) {
+	opc, err := OPc(k, op)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWithOPc(k, opc)
+}
+
+// NewWithOPc returns Milenage keyed with K and an OPc already computed, as stored in the USIM.
+func NewWithOPc(k []byte, opc []byte) (*Milenage, error) {
+	if len(k) != KeySize || len(opc) != KeySize {
+		return nil, ErrKeySize
+	}
+
+	block, err := aes.NewCipher(k)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Milenage{block: block}
+	copy(m.opc[:], opc)
+
+	return m, nil
+}
+
+// rotate cyclically rotates x by r bytes towards the most significant byte.
+func rotate(x [16]byte, r int) [16]byte {
+	out := [16]byte{}
+	for i := range out {
+		out[i] = x[(i+r)%16]
+	}
+
+	return out
+}
+
+func (m *Milenage) temp(rand []byte) [16]byte {
+	t := [16]byte{}
+	for i := range t {
+		t[i] = rand[i] ^ m.opc[i]
+	}
+	m.block.Encrypt(t[:], t[:])
+
+	return t
+}
+
+// out computes OUTn = E_K(rot(x ⊕ OPc, r) ⊕ c) ⊕ OPc, where c has its last octet set to c.
+func (m *Milenage) out(x [16]byte, r int, c uint8) [16]byte {
+	for i := range x {
+		x[i] ^= m.opc[i]
+	}
+
+	x = rotate(x, r)
+	x[15] ^= c
+
+	m.block.Encrypt(x[:], x[:])
+	for i := range x {
+		x[i] ^= m.opc[i]
+	}
+
+	return x
+}
+
+func (m *Milenage) out1(rand []byte, sqn []byte, amf []byte) ([16]byte, error) {
+	if len(rand) != RANDSize {
+		return [16]byte{}, ErrRANDSize
+	}
+
+	if len(sqn) != SQNSize {
+		return [16]byte{}, ErrSQNSize
+	}
+
+	if len(amf) != AMFSize {
+		return [16]byte{}, ErrAMFSize
+	}
+
+	in1 := [16]byte{}
+	copy(in1[0:], sqn)
+	copy(in1[6:], amf)
+	copy(in1[8:], sqn)
+	copy(in1[14:], amf)
+
+	temp := m.temp(rand)
+
+	// OUT1 = E_K(TEMP ⊕ rot(IN1 ⊕ OPc, r1) ⊕ c1) ⊕ OPc, with r1 = 64 and c1 = 0.
+	x := [16]byte{}
+	for i := range in1 {
+		x[i] = in1[i] ^ m.opc[i]
+	}
+
+	x = rotate(x, 8)
+	for i := range x {
+		x[i] ^= temp[i]
+	}
+
+	m.block.Encrypt(x[:], x[:])
+	for i := range x {
+		x[i] ^= m.opc[i]
+	}
+
+	return x, nil
+}
+
+// F1 computes the network authentication code MAC-A.
+func (m *Milenage) F1(rand []byte, sqn []byte, amf []byte) ([]byte, error) {
+	out, err := m.out1(rand, sqn, amf)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{}, out[:8]...), nil
+}
+
+// F1Star computes the resynchronisation authentication code MAC-S.
+func (m *Milenage) F1Star(rand []byte, sqn []byte, amf []byte) ([]byte, error) {
+	out, err := m.out1(rand, sqn, amf)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{}, out[8:]...), nil
+}
+
+// F2345 computes RES (f2), CK (f3), IK (f4) and AK (f5).
+func (m *Milenage) F2345(rand []byte) ([]byte, []byte, []byte, []byte, error) {
+	if len(rand) != RANDSize {
+		return nil, nil, nil, nil, ErrRANDSize
+	}
+
+	temp := m.temp(rand)
+
+	out2 := m.out(temp, 0, 1)
+	out3 := m.out(temp, 4, 2)
+	out4 := m.out(temp, 8, 4)
+
+	res := append([]byte{}, out2[8:]...)
+	ak := append([]byte{}, out2[:6]...)
+
+	return res, out3[:], out4[:], ak, nil
+}
+
+// F5Star computes the resynchronisation anonymity key AK.
+func (m *Milenage) F5Star(rand []byte) ([]byte, error) {
+	if len(rand) != RANDSize {
+		return nil, ErrRANDSize
+	}
+
+	out5 := m.out(m.temp(rand), 12, 8)
+
+	return append([]byte{}, out5[:6]...), nil
+}
+
+// Vector computes the authentication vector for RAND, SQN and AMF, with AUTN = SQN ⊕ AK || AMF || MAC-A.
+func (m *Milenage) Vector(rand []byte, sqn []byte, amf []byte) (*Vector, error) {
+	mac, err := m.F1(rand, sqn, amf)
+	if err != nil {
+		return nil, err
+	}
+
+	res, ck, ik, ak, err := m.F2345(rand)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &Vector{
+		RAND:     append([]byte{}, rand...),
+		XRES:     res,
+		CK:       ck,
+		IK:       ik,
+		AK:       ak,
+		SQNXorAK: make([]byte, SQNSize),
+	}
+
+	for i := range v.SQNXorAK {
+		v.SQNXorAK[i] = sqn[i] ^ ak[i]
+	}
+
+	v.AUTN = make([]byte, 0, SQNSize+AMFSize+len(mac))
+	v.AUTN = append(v.AUTN, v.SQNXorAK...)
+	v.AUTN = append(v.AUTN, amf...)
+	v.AUTN = append(v.AUTN, mac...)
+
+	return v, nil
+}
@@ -0,0 +1,209 @@
+package milenage
+
+import (
+	"encoding/hex"
+	"github.com/frankurcrazy/zuc"
+	"github.com/frankurcrazy/zuc/eea3"
+	"github.com/frankurcrazy/zuc/kdf"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func decode(s string) []byte {
+	b, _ := hex.DecodeString(strings.Join(strings.Fields(s), ""))
+
+	return b
+}
+
+type TestSet struct {
+	K      string
+	RAND   string
+	SQN    string
+	AMF    string
+	OP     string
+	OPc    string
+	F1     string
+	F1Star string
+	F2     string
+	F5     string
+	F3     string
+	F4     string
+	F5Star string
+}
+
+var testSets = map[string]TestSet{
+	"4.3 Test Set 1": TestSet{
+		K:      "465b5ce8 b199b49f aa5f0a2e e238a6bc",
+		RAND:   "23553cbe 9637a89d 218ae64d ae47bf35",
+		SQN:    "ff9bb4d0 b607",
+		AMF:    "b9b9",
+		OP:     "cdc202d5 123e20f6 2b6d676a c72cb318",
+		OPc:    "cd63cb71 954a9f4e 48a5994e 37a02baf",
+		F1:     "4a9ffac3 54dfafb3",
+		F1Star: "01cfaf9e c4e871e9",
+		F2:     "a54211d5 e3ba50bf",
+		F5:     "aa689c64 8370",
+		F3:     "b40ba9a3 c58b2a05 bbf0d987 b21bf8cb",
+		F4:     "f769bcd7 51044604 12767271 1c6d3441",
+		F5Star: "451e8bec a43b",
+	},
+	"4.3 Test Set 2": TestSet{
+		K:      "0396eb31 7b6d1c36 f19c1c84 cd6ffd16",
+		RAND:   "c00d6031 03dcee52 c4478119 494202e8",
+		SQN:    "fd8eef40 df7d",
+		AMF:    "af17",
+		OP:     "ff53bade 17df5d4e 793073ce 9d7579fa",
+		OPc:    "53c15671 c60a4b73 1c55b4a4 41c0bde2",
+		F1:     "5df5b318 07e258b0",
+		F1Star: "a8c016e5 1ef4a343",
+		F2:     "d3a628ed 988620f0",
+		F5:     "c4778399 5f72",
+		F3:     "58c433ff 7a7082ac d424220f 2b67c556",
+		F4:     "21a8c1f9 29702adb 3e738488 b9f5c5da",
+		F5Star: "30f11970 61c1",
+	},
+	"4.3 Test Set 3": TestSet{
+		K:      "fec86ba6 eb707ed0 8905757b 1bb44b8f",
+		RAND:   "9f7c8d02 1accf4db 213ccff0 c7f71a6a",
+		SQN:    "9d027759 5ffc",
+		AMF:    "725c",
+		OP:     "dbc59adc b6f9a0ef 735477b7 fadf8374",
+		OPc:    "1006020f 0a478bf6 b699f15c 062e42b3",
+		F1:     "9cabc3e9 9baf7281",
+		F1Star: "95814ba2 b3044324",
+		F2:     "8011c48c 0c214ed2",
+		F5:     "33484dc2 136b",
+		F3:     "5dbdbb29 54e8f3cd e665b046 179a5098",
+		F4:     "59a92d3b 476a0443 487055cf 88b2307b",
+		F5Star: "deacdd84 8cc6",
+	},
+	"4.3 Test Set 4": TestSet{
+		K:      "9e5944ae a94b8116 5c82fbf9 f32db751",
+		RAND:   "ce83dbc5 4ac0274a 157c17f8 0d017bd6",
+		SQN:    "0b604a81 eca8",
+		AMF:    "9e09",
+		OP:     "223014c5 806694c0 07ca1eee f57f004f",
+		OPc:    "a64a507a e1a2a98b b88eb421 0135dc87",
+		F1:     "74a58220 cba84c49",
+		F1Star: "ac2cc74a 96871837",
+		F2:     "f365cd68 3cd92e96",
+		F5:     "f0b9c08a d02e",
+		F3:     "e203edb3 971574f5 a94b0d61 b816345d",
+		F4:     "0c4524ad eac041c4 dd830d20 854fc46b",
+		F5Star: "6085a86c 6f63",
+	},
+	"4.3 Test Set 5": TestSet{
+		K:      "4ab1deb0 5ca6ceb0 51fc98e7 7d026a84",
+		RAND:   "74b0cd60 31a1c833 9b2b6ce2 b8c4a186",
+		SQN:    "e880a1b5 80b6",
+		AMF:    "9f07",
+		OP:     "2d16c5cd 1fdf6b22 383584e3 bef2a8d8",
+		OPc:    "dcf07cbd 51855290 b92a07a9 891e523e",
+		F1:     "49e785dd 12626ef2",
+		F1Star: "9e857903 36bb3fa2",
+		F2:     "5860fc1b ce351e7e",
+		F5:     "31e11a60 9118",
+		F3:     "7657766b 373d1c21 38f307e3 de9242f9",
+		F4:     "1c42e960 d89b8fa9 9f2744e0 708ccb53",
+		F5Star: "fe2555e5 4aa9",
+	},
+	"4.3 Test Set 6": TestSet{
+		K:      "6c38a116 ac280c45 4f59332e e35c8c4f",
+		RAND:   "ee6466bc 96202c5a 557abbef f8babf63",
+		SQN:    "414b9822 2181",
+		AMF:    "4464",
+		OP:     "1ba00a1a 7c6700ac 8c3ff3e9 6ad08725",
+		OPc:    "3803ef53 63b947c6 aaa225e5 8fae3934",
+		F1:     "078adfb4 88241a57",
+		F1Star: "80246b8d 0186bcf1",
+		F2:     "16c8233f 05a0ac28",
+		F5:     "45b0f69a b06c",
+		F3:     "3f8c7587 fe8e4b23 3af676ae de30ba3b",
+		F4:     "a7466cc1 e6b2a133 7d49d3b6 6e95d7b4",
+		F5Star: "1f53cd2b 1113",
+	},
+}
+
+func TestMilenage(t *testing.T) {
+	for n, ts := range testSets {
+		t.Run(n, func(t *testing.T) {
+			opc, err := OPc(decode(ts.K), decode(ts.OP))
+			assert.Nil(t, err)
+			assert.Equal(t, decode(ts.OPc), opc)
+
+			m, err := New(decode(ts.K), decode(ts.OP))
+			assert.Nil(t, err)
+
+			mac, err := m.F1(decode(ts.RAND), decode(ts.SQN), decode(ts.AMF))
+			assert.Nil(t, err)
+			assert.Equal(t, decode(ts.F1), mac)
+
+			macS, err := m.F1Star(decode(ts.RAND), decode(ts.SQN), decode(ts.AMF))
+			assert.Nil(t, err)
+			assert.Equal(t, decode(ts.F1Star), macS)
+
+			res, ck, ik, ak, err := m.F2345(decode(ts.RAND))
+			assert.Nil(t, err)
+			assert.Equal(t, decode(ts.F2), res)
+			assert.Equal(t, decode(ts.F3), ck)
+			assert.Equal(t, decode(ts.F4), ik)
+			assert.Equal(t, decode(ts.F5), ak)
+
+			akS, err := m.F5Star(decode(ts.RAND))
+			assert.Nil(t, err)
+			assert.Equal(t, decode(ts.F5Star), akS)
+
+			withOPc, err := NewWithOPc(decode(ts.K), decode(ts.OPc))
+			assert.Nil(t, err)
+			assert.Equal(t, m, withOPc)
+		})
+	}
+}
+
+func TestVector(t *testing.T) {
+	ts := testSets["4.3 Test Set 1"]
+	m, _ := New(decode(ts.K), decode(ts.OP))
+
+	v, err := m.Vector(decode(ts.RAND), decode(ts.SQN), decode(ts.AMF))
+	assert.Nil(t, err)
+	assert.Equal(t, decode("55f328b43577"), v.SQNXorAK)
+	assert.Equal(t, decode("55f328b43577 b9b9 4a9ffac354dfafb3"), v.AUTN)
+	assert.Equal(t, decode(ts.F2), v.XRES)
+
+	// From AKA to a NAS ciphering key keying 128-EEA3.
+	kasme := kdf.KASME(v.CK, v.IK, kdf.ServingNetworkID("001", "01"), v.SQNXorAK)
+	knasenc := kdf.KNASenc(kasme, kdf.AlgorithmEEA3)
+	assert.Equal(t, 16, len(knasenc))
+
+	msg := []byte("attach accept")
+	ciphertext := eea3.NewEEA3(knasenc, 0, 0, zuc.KEY_DOWNLINK).Encrypt(msg, uint32(len(msg))*8)
+	assert.Equal(t, msg, eea3.NewEEA3(knasenc, 0, 0, zuc.KEY_DOWNLINK).Decrypt(ciphertext, uint32(len(msg))*8))
+}
+
+func TestErrors(t *testing.T) {
+	k := decode("465b5ce8b199b49faa5f0a2ee238a6bc")
+
+	_, err := New(k[:8], k)
+	assert.Equal(t, ErrKeySize, err)
+
+	_, err = NewWithOPc(k, k[:15])
+	assert.Equal(t, ErrKeySize, err)
+
+	m, _ := New(k, k)
+
+	_, err = m.F1(k[:8], make([]byte, SQNSize), make([]byte, AMFSize))
+	assert.Equal(t, ErrRANDSize, err)
+
+	_, err = m.F1(k, make([]byte, 5), make([]byte, AMFSize))
+	assert.Equal(t, ErrSQNSize, err)
+
+	_, err = m.F1Star(k, make([]byte, SQNSize), make([]byte, 3))
+	assert.Equal(t, ErrAMFSize, err)
+
+	_, _, _, _, err = m.F2345(k[:4])
+	assert.Equal(t, ErrRANDSize, err)
+
+	_, err = m.F5Star(nil)
+	assert.Equal(t, ErrRANDSize, err)
+}
@@ -0,0 +1,355 @@
+// Security-protected NAS messages for EPS (3GPP TS 24.301 clause 9.1) and 5GS (3GPP TS 24.501
+// clause 9.1.1), protected with 128-EEA3/EIA3 and 128-NEA3/NIA3 as in TS 33.401 clause 8 and
+// TS 33.501 clause 6.4. The plain NAS message is ciphered, then the MAC is computed over the
+// sequence number and the ciphered message.
+
+package nas
+
+import (
+	"crypto/subtle"
+	"errors"
+	"github.com/frankurcrazy/zuc"
+	"github.com/frankurcrazy/zuc/eea3"
+	"github.com/frankurcrazy/zuc/eia3"
+	"sync"
+)
+
+type System int
+
+const (
+	EPS = System(iota)
+	FiveGS
+)
+
+type SecurityHeaderType uint8
+
+const (
+	Plain = SecurityHeaderType(iota)
+	IntegrityProtected
+	IntegrityProtectedAndCiphered
+	IntegrityProtectedNewContext
+	IntegrityProtectedAndCipheredNewContext
+)
+
+const (
+	// ProtocolDiscriminatorEMM is the EPS mobility management protocol discriminator.
+	ProtocolDiscriminatorEMM = uint8(0x07)
+	// EPD5GMM is the 5GS mobility management extended protocol discriminator.
+	EPD5GMM = uint8(0x7e)
+
+	KeySize  = 16
+	MACSize  = 4
+	MaxCount = 1<<24 - 1
+)
+
+// Connection identifiers used as BEARER for 5GS NAS, TS 33.501 clause 6.4.3.1. EPS NAS always uses 0.
+const (
+	Bearer3GPP    = uint32(0)
+	BearerNon3GPP = uint32(1)
+)
+
+var (
+	ErrKeySize         = errors.New("nas: keys must be 16 bytes")
+	ErrBearer          = errors.New("nas: invalid BEARER for this system")
+	ErrHeaderType      = errors.New("nas: unsupported security header type")
+	ErrMessageTooShort = errors.New("nas: message too short")
+	ErrDiscriminator   = errors.New("nas: unexpected protocol discriminator")
+	ErrCountExhausted  = errors.New("nas: NAS COUNT exhausted, rekeying required")
+	ErrReplay          = errors.New("nas: replayed NAS COUNT")
+	ErrCountGap        = errors.New("nas: NAS COUNT gap without integrity protection")
+	ErrIntegrityFailed = errors.New("nas: MAC verification failed")
+)
+
+// Header is the security header preceding the protected NAS message.
+type Header struct {
+	Type SecurityHeaderType
+	MAC  [MACSize]byte
+	SN   uint8
+}
+
+// HeaderLength returns the length of the security protected NAS message header for system.
+func HeaderLength(system System) int {
+	if system == FiveGS {
+		return 7
+	}
+
+	return 6
+}
+
+// Count builds NAS COUNT from the overflow counter and the sequence number.
+func Count(overflow uint16, sn uint8) uint32 {
+	return uint32(overflow)<<8 | uint32(sn)
+}
+
+// ParseHeader splits a security protected NAS message into its header and the, possibly
+// ciphered, NAS message it carries.
+func ParseHeader(system System, pdu []byte) (Header, []byte, error) {
+	h := Header{}
+
+	hlen := HeaderLength(system)
+	if len(pdu) < hlen {
+		return h, nil, ErrMessageTooShort
+	}
+
+	if system == FiveGS {
+		if pdu[0] != EPD5GMM {
+			return h, nil, ErrDiscriminator
+		}
+
+		h.Type = SecurityHeaderType(pdu[1] & 0x0f)
+	} else {
+		if pdu[0]&0x0f != ProtocolDiscriminatorEMM {
+			return h, nil, ErrDiscriminator
+		}
+
+		h.Type = SecurityHeaderType(pdu[0] >> 4)
+	}
+
+	if h.Type == Plain || h.Type > IntegrityProtectedAndCipheredNewContext {
+		return h, nil, ErrHeaderType
+	}
+
+	copy(h.MAC[:], pdu[hlen-MACSize-1:])
+	h.SN = pdu[hlen-1]
+
+	return h, pdu[hlen:], nil
+}
+
+func buildHeader(system System, h Header) []byte {
+	header := make([]byte, HeaderLength(system))
+
+	if system == FiveGS {
+		header[0] = EPD5GMM
+		header[1] = uint8(h.Type)
+	} else {
+		header[0] = uint8(h.Type)<<4 | ProtocolDiscriminatorEMM
+	}
+
+	copy(header[len(header)-MACSize-1:], h.MAC[:])
+	header[len(header)-1] = h.SN
+
+	return header
+}
+
+func ciphered(t SecurityHeaderType) bool {
+	return t == IntegrityProtectedAndCiphered || t == IntegrityProtectedAndCipheredNewContext
+}
+
+// Context holds the NAS keys and the uplink and downlink NAS COUNTs of one NAS connection.
+// A nil key selects the null algorithm.
+type Context struct {
+	system       System
+	cipherKey    []byte
+	integrityKey []byte
+	bearer       uint32
+
+	mu       sync.Mutex
+	count    [2]uint32
+	last     [2]uint32
+	received [2]bool
+}
+
+func NewContext(system System, cipherKey []byte, integrityKey []byte, bearer uint32) (*Context, error) {
+	if cipherKey != nil && len(cipherKey) != KeySize {
+		return nil, ErrKeySize
+	}
+
+	if integrityKey != nil && len(integrityKey) != KeySize {
+		return nil, ErrKeySize
+	}
+
+	if (system == EPS && bearer != 0) || (system == FiveGS && bearer > BearerNon3GPP) {
+		return nil, ErrBearer
+	}
+
+	c := &Context{
+		system: system,
+		bearer: bearer,
+	}
+
+	if cipherKey != nil {
+		c.cipherKey = append([]byte{}, cipherKey...)
+	}
+
+	if integrityKey != nil {
+		c.integrityKey = append([]byte{}, integrityKey...)
+	}
+
+	return c, nil
+}
+
+// Count returns the next NAS COUNT for direction dir.
+func (c *Context) Count(dir zuc.KeyDirection) uint32 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.count[dir&1]
+}
+
+// SetCount sets the next NAS COUNT for direction dir, e.g. when a new security context is taken into use.
+func (c *Context) SetCount(dir zuc.KeyDirection, count uint32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.count[dir&1] = count & MaxCount
+	c.received[dir&1] = false
+}
+
+func (c *Context) mac(count uint32, dir zuc.KeyDirection, m []byte) ([MACSize]byte, error) {
+	mac := [MACSize]byte{}
+	if err := eia3.ValidateLength(m, uint64(len(m))*8); err != nil {
+		return mac, err
+	}
+
+	if c.integrityKey != nil {
+		copy(mac[:], eia3.NewEIA3(c.integrityKey, count, c.bearer, dir).Hash(m, uint32(len(m))*8))
+	}
+
+	return mac, nil
+}
+
+func (c *Context) cipher(count uint32, dir zuc.KeyDirection, m []byte) ([]byte, error) {
+	if err := eea3.ValidateLength(m, uint64(len(m))*8); err != nil {
+		return nil, err
+	}
+
+	if c.cipherKey == nil {
+		return append([]byte{}, m...), nil
+	}
+
+	return eea3.NewEEA3(c.cipherKey, count, c.bearer, dir).Encrypt(m, uint32(len(m))*8), nil
+}
+
+// Protect builds the security protected NAS message for msg with the next NAS COUNT of direction dir.
+func (c *Context) Protect(dir zuc.KeyDirection, t SecurityHeaderType, msg []byte) ([]byte, error) {
+	if t == Plain || t > IntegrityProtectedAndCipheredNewContext {
+		return nil, ErrHeaderType
+	}
+
+	dir &= 1
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	count := c.count[dir]
+	if count > MaxCount {
+		return nil, ErrCountExhausted
+	}
+
+	body := make([]byte, 0, 1+len(msg))
+	body = append(body, uint8(count))
+	if ciphered(t) {
+		m, err := c.cipher(count, dir, msg)
+		if err != nil {
+			return nil, err
+		}
+
+		body = append(body, m...)
+	} else {
+		body = append(body, msg...)
+	}
+
+	mac, err := c.mac(count, dir, body)
+	if err != nil {
+		return nil, err
+	}
+
+	h := Header{Type: t, MAC: mac, SN: uint8(count)}
+	pdu := append(buildHeader(c.system, h), body[1:]...)
+
+	c.count[dir] = count + 1
+
+	return pdu, nil
+}
+
+// estimate rebuilds NAS COUNT from a received SN, TS 24.301 clause 4.4.3.1. A SN lower than that
+// of the next expected NAS COUNT is taken to follow a wraparound, and is estimated with the
+// overflow counter incremented.
+func (c *Context) estimate(dir zuc.KeyDirection, sn uint8) uint32 {
+	next := c.count[dir]
+	count := next&^0xff | uint32(sn)
+	if sn < uint8(next) {
+		count += 0x100
+	}
+
+	return count
+}
+
+func (c *Context) verify(dir zuc.KeyDirection, count uint32, signed []byte, mac [MACSize]byte) error {
+	if count > MaxCount {
+		return ErrCountExhausted
+	}
+
+	if c.integrityKey == nil && count != c.count[dir] {
+		return ErrCountGap
+	}
+
+	expected, err := c.mac(count, dir, signed)
+	if err != nil {
+		return err
+	}
+
+	if subtle.ConstantTimeCompare(expected[:], mac[:]) != 1 {
+		return ErrIntegrityFailed
+	}
+
+	return nil
+}
+
+// replayed reports whether a message that failed verification with the estimated NAS COUNT
+// verifies with the NAS COUNT of the same SN one overflow earlier, at or below the highest
+// accepted NAS COUNT.
+func (c *Context) replayed(dir zuc.KeyDirection, count uint32, signed []byte, mac [MACSize]byte) bool {
+	if c.integrityKey == nil || !c.received[dir] || count < 0x100 || count-0x100 > c.last[dir] {
+		return false
+	}
+
+	expected, err := c.mac(count-0x100, dir, signed)
+
+	return err == nil && subtle.ConstantTimeCompare(expected[:], mac[:]) == 1
+}
+
+// Unprotect verifies and deciphers a security protected NAS message received in direction dir.
+// The estimated NAS COUNT only advances when the MAC verifies. A message that fails verification
+// but verifies with an already accepted NAS COUNT is reported as ErrReplay. Without an integrity
+// key the all-zero MAC of the null algorithm is required and only the next expected NAS COUNT is
+// accepted, since nothing authenticates a jump in SN or tells a replay from it.
+func (c *Context) Unprotect(dir zuc.KeyDirection, pdu []byte) (SecurityHeaderType, []byte, error) {
+	h, body, err := ParseHeader(c.system, pdu)
+	if err != nil {
+		return h.Type, nil, err
+	}
+
+	dir &= 1
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	count := c.estimate(dir, h.SN)
+
+	signed := make([]byte, 0, 1+len(body))
+	signed = append(signed, h.SN)
+	signed = append(signed, body...)
+
+	if err := c.verify(dir, count, signed, h.MAC); err != nil {
+		if c.replayed(dir, count, signed, h.MAC) {
+			return h.Type, nil, ErrReplay
+		}
+
+		return h.Type, nil, err
+	}
+
+	msg := append([]byte{}, body...)
+	if ciphered(h.Type) {
+		msg, err = c.cipher(count, dir, body)
+		if err != nil {
+			return h.Type, nil, err
+		}
+	}
+
+	c.count[dir] = count + 1
+	c.last[dir] = count
+	c.received[dir] = true
+
+	return h.Type, msg, nil
+}
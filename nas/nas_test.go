@@ -0,0 +1,254 @@
+package nas
+
+import (
+	"encoding/hex"
+	"github.com/frankurcrazy/zuc"
+	"github.com/frankurcrazy/zuc/eea3"
+	"github.com/frankurcrazy/zuc/eia3"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var (
+	ck, _ = hex.DecodeString("173d14ba5003731d7a60049470f00a29")
+	ik, _ = hex.DecodeString("c9e6cec4607c72db000aefa88385ab0a")
+)
+
+func TestNewContext(t *testing.T) {
+	_, err := NewContext(EPS, ck[:8], ik, 0)
+	assert.Equal(t, ErrKeySize, err)
+
+	_, err = NewContext(EPS, ck, ik, 1)
+	assert.Equal(t, ErrBearer, err)
+
+	_, err = NewContext(FiveGS, ck, ik, BearerNon3GPP)
+	assert.Nil(t, err)
+
+	_, err = NewContext(FiveGS, ck, ik, 2)
+	assert.Equal(t, ErrBearer, err)
+}
+
+func TestProtect(t *testing.T) {
+	type TestSet struct {
+		System System
+		Bearer uint32
+		Header string
+	}
+
+	testSets := map[string]TestSet{
+		"EPS":          {EPS, 0, "27"},
+		"5GS 3GPP":     {FiveGS, Bearer3GPP, "7e02"},
+		"5GS non-3GPP": {FiveGS, BearerNon3GPP, "7e02"},
+	}
+
+	// Security mode complete carrying no IEs, with NAS COUNT 0x000105.
+	msg, _ := hex.DecodeString("075e")
+	count := Count(1, 5)
+
+	for name, set := range testSets {
+		ue, _ := NewContext(set.System, ck, ik, set.Bearer)
+		ue.SetCount(zuc.KEY_UPLINK, count)
+
+		pdu, err := ue.Protect(zuc.KEY_UPLINK, IntegrityProtectedAndCiphered, msg)
+		assert.Nil(t, err, name)
+		assert.Equal(t, count+1, ue.Count(zuc.KEY_UPLINK), name)
+
+		// Compose the same message from the algorithms directly.
+		body := append([]byte{0x05}, eea3.NewEEA3(ck, count, set.Bearer, zuc.KEY_UPLINK).Encrypt(msg, 16)...)
+		mac := eia3.NewEIA3(ik, count, set.Bearer, zuc.KEY_UPLINK).Hash(body, uint32(len(body))*8)
+		header, _ := hex.DecodeString(set.Header)
+		expected := append(append(header, mac...), body...)
+		assert.Equal(t, expected, pdu, name)
+
+		network, _ := NewContext(set.System, ck, ik, set.Bearer)
+		network.SetCount(zuc.KEY_UPLINK, count)
+
+		typ, out, err := network.Unprotect(zuc.KEY_UPLINK, pdu)
+		assert.Nil(t, err, name)
+		assert.Equal(t, IntegrityProtectedAndCiphered, typ, name)
+		assert.Equal(t, msg, out, name)
+
+		_, _, err = network.Unprotect(zuc.KEY_UPLINK, pdu)
+		assert.Equal(t, ErrReplay, err, name)
+	}
+}
+
+func TestIntegrityOnly(t *testing.T) {
+	msg := []byte{0x07, 0x5d, 0x33, 0x00, 0x02, 0xf0, 0x70}
+
+	network, _ := NewContext(EPS, ck, ik, 0)
+	ue, _ := NewContext(EPS, ck, ik, 0)
+
+	pdu, err := network.Protect(zuc.KEY_DOWNLINK, IntegrityProtectedNewContext, msg)
+	assert.Nil(t, err)
+	assert.Equal(t, msg, pdu[HeaderLength(EPS):])
+	assert.Equal(t, uint8(0x37), pdu[0])
+
+	typ, out, err := ue.Unprotect(zuc.KEY_DOWNLINK, pdu)
+	assert.Nil(t, err)
+	assert.Equal(t, IntegrityProtectedNewContext, typ)
+	assert.Equal(t, msg, out)
+}
+
+func TestUnprotectErrors(t *testing.T) {
+	ue, _ := NewContext(FiveGS, ck, ik, Bearer3GPP)
+	network, _ := NewContext(FiveGS, ck, ik, Bearer3GPP)
+
+	pdu, _ := ue.Protect(zuc.KEY_UPLINK, IntegrityProtectedAndCiphered, []byte{0x7e, 0x00, 0x5e})
+
+	for i := 2; i < len(pdu); i += 1 {
+		tampered := append([]byte{}, pdu...)
+		tampered[i] ^= 0x80
+		_, _, err := network.Unprotect(zuc.KEY_UPLINK, tampered)
+		assert.Equal(t, ErrIntegrityFailed, err, i)
+	}
+
+	// Failures do not advance NAS COUNT.
+	assert.Equal(t, uint32(0), network.Count(zuc.KEY_UPLINK))
+
+	_, _, err := network.Unprotect(zuc.KEY_UPLINK, pdu[:6])
+	assert.Equal(t, ErrMessageTooShort, err)
+
+	bad := append([]byte{}, pdu...)
+	bad[0] = 0x2e
+	_, _, err = network.Unprotect(zuc.KEY_UPLINK, bad)
+	assert.Equal(t, ErrDiscriminator, err)
+
+	bad[0], bad[1] = EPD5GMM, 0x00
+	_, _, err = network.Unprotect(zuc.KEY_UPLINK, bad)
+	assert.Equal(t, ErrHeaderType, err)
+
+	_, err = ue.Protect(zuc.KEY_UPLINK, Plain, []byte{0x7e})
+	assert.Equal(t, ErrHeaderType, err)
+}
+
+func TestMessageTooLong(t *testing.T) {
+	ue, _ := NewContext(EPS, ck, ik, 0)
+
+	_, err := ue.Protect(zuc.KEY_UPLINK, IntegrityProtectedAndCiphered, make([]byte, eea3.MaxLength/8+1))
+	assert.Equal(t, eea3.ErrLength, err)
+
+	// The MAC also covers the SN.
+	_, err = ue.Protect(zuc.KEY_UPLINK, IntegrityProtected, make([]byte, eia3.MaxLength/8))
+	assert.Equal(t, eia3.ErrMessageTooLong, err)
+	assert.Equal(t, uint32(0), ue.Count(zuc.KEY_UPLINK))
+
+	network, _ := NewContext(EPS, ck, ik, 0)
+	pdu := append([]byte{0x27, 0, 0, 0, 0, 0}, make([]byte, eia3.MaxLength/8)...)
+	_, _, err = network.Unprotect(zuc.KEY_UPLINK, pdu)
+	assert.Equal(t, eia3.ErrMessageTooLong, err)
+}
+
+func TestCountEstimation(t *testing.T) {
+	ue, _ := NewContext(EPS, ck, ik, 0)
+	network, _ := NewContext(EPS, ck, ik, 0)
+
+	ue.SetCount(zuc.KEY_UPLINK, Count(2, 0xfe))
+	network.SetCount(zuc.KEY_UPLINK, Count(2, 0xfe))
+
+	// Across the SN wraparound, and past a lost message.
+	pdus := [][]byte{}
+	for i := 0; i < 4; i += 1 {
+		pdu, err := ue.Protect(zuc.KEY_UPLINK, IntegrityProtectedAndCiphered, []byte{0x07, 0x4e, uint8(i)})
+		assert.Nil(t, err)
+		pdus = append(pdus, pdu)
+	}
+
+	for _, i := range []int{0, 1, 3} {
+		_, out, err := network.Unprotect(zuc.KEY_UPLINK, pdus[i])
+		assert.Nil(t, err, i)
+		assert.Equal(t, []byte{0x07, 0x4e, uint8(i)}, out, i)
+	}
+
+	assert.Equal(t, Count(3, 0x02), network.Count(zuc.KEY_UPLINK))
+
+	// Accepted or skipped NAS COUNTs are replays, whatever their SN.
+	for _, i := range []int{0, 2, 3} {
+		_, _, err := network.Unprotect(zuc.KEY_UPLINK, pdus[i])
+		assert.Equal(t, ErrReplay, err, i)
+	}
+
+	assert.Equal(t, Count(3, 0x02), network.Count(zuc.KEY_UPLINK))
+
+	// The last accepted SN is a replay even when the next expected SN wrapped to 0.
+	ue.SetCount(zuc.KEY_UPLINK, Count(4, 0xff))
+	network.SetCount(zuc.KEY_UPLINK, Count(4, 0xff))
+
+	pdu, _ := ue.Protect(zuc.KEY_UPLINK, IntegrityProtected, []byte{0x07})
+	_, _, err := network.Unprotect(zuc.KEY_UPLINK, pdu)
+	assert.Nil(t, err)
+
+	_, _, err = network.Unprotect(zuc.KEY_UPLINK, pdu)
+	assert.Equal(t, ErrReplay, err)
+
+	ue.SetCount(zuc.KEY_UPLINK, MaxCount)
+	_, err = ue.Protect(zuc.KEY_UPLINK, IntegrityProtected, []byte{0x07})
+	assert.Nil(t, err)
+
+	_, err = ue.Protect(zuc.KEY_UPLINK, IntegrityProtected, []byte{0x07})
+	assert.Equal(t, ErrCountExhausted, err)
+}
+
+func TestLossAcrossWraparound(t *testing.T) {
+	ue, _ := NewContext(EPS, ck, ik, 0)
+	network, _ := NewContext(EPS, ck, ik, 0)
+
+	ue.SetCount(zuc.KEY_UPLINK, Count(0, 0xfe))
+	network.SetCount(zuc.KEY_UPLINK, Count(0, 0xfe))
+
+	pdus := [][]byte{}
+	for i := 0; i < 6; i += 1 {
+		pdu, _ := ue.Protect(zuc.KEY_UPLINK, IntegrityProtectedAndCiphered, []byte{0x07, 0x4e, uint8(i)})
+		pdus = append(pdus, pdu)
+	}
+
+	// SNs 0xfe and 0xff are lost, the next SN 0x00 is estimated past the wraparound.
+	for _, i := range []int{2, 3, 5} {
+		_, out, err := network.Unprotect(zuc.KEY_UPLINK, pdus[i])
+		assert.Nil(t, err, i)
+		assert.Equal(t, []byte{0x07, 0x4e, uint8(i)}, out, i)
+	}
+
+	assert.Equal(t, Count(1, 0x04), network.Count(zuc.KEY_UPLINK))
+
+	for _, i := range []int{0, 1, 4, 5} {
+		_, _, err := network.Unprotect(zuc.KEY_UPLINK, pdus[i])
+		assert.Equal(t, ErrReplay, err, i)
+	}
+
+	assert.Equal(t, Count(1, 0x04), network.Count(zuc.KEY_UPLINK))
+}
+
+func TestNullIntegrity(t *testing.T) {
+	ue, _ := NewContext(EPS, nil, nil, 0)
+	network, _ := NewContext(EPS, nil, nil, 0)
+
+	pdus := [][]byte{}
+	for i := 0; i < 3; i += 1 {
+		pdu, err := ue.Protect(zuc.KEY_UPLINK, IntegrityProtectedAndCiphered, []byte{0x07, 0x4e, uint8(i)})
+		assert.Nil(t, err)
+		pdus = append(pdus, pdu)
+	}
+
+	_, out, err := network.Unprotect(zuc.KEY_UPLINK, pdus[0])
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0x07, 0x4e, 0x00}, out)
+
+	// Without a MAC to check, a replay cannot be told from a jump in SN. Neither is accepted, and
+	// neither moves NAS COUNT.
+	for _, i := range []int{0, 2} {
+		_, _, err = network.Unprotect(zuc.KEY_UPLINK, pdus[i])
+		assert.Equal(t, ErrCountGap, err, i)
+	}
+
+	assert.Equal(t, uint32(1), network.Count(zuc.KEY_UPLINK))
+
+	forged := append([]byte{}, pdus[1]...)
+	forged[1] = 0x01
+	_, _, err = network.Unprotect(zuc.KEY_UPLINK, forged)
+	assert.Equal(t, ErrIntegrityFailed, err)
+
+	_, _, err = network.Unprotect(zuc.KEY_UPLINK, pdus[1])
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(2), network.Count(zuc.KEY_UPLINK))
+}
@@ -0,0 +1,39 @@
+// 128-NEA3 as specified in 3GPP TS 33.501 Annex D.2.
+// 128-NEA3 is identical to 128-EEA3, the 5-bit BEARER being derived from the NR radio bearer identity.
+
+package nea3
+
+import (
+	"errors"
+	"github.com/frankurcrazy/zuc"
+	"github.com/frankurcrazy/zuc/eea3"
+)
+
+var ErrRBIdentity = errors.New("nea3: radio bearer identity must be in 1..32")
+
+type NEA3 struct {
+	eea3 *eea3.EEA3
+}
+
+// BearerFromRBIdentity maps an NR radio bearer identity (1..32) to the 5-bit BEARER input (RB identity - 1).
+// It is shared by 128-NEA3 and 128-NIA3.
+func BearerFromRBIdentity(rbIdentity uint32) (uint32, error) {
+	if rbIdentity < 1 || rbIdentity > 32 {
+		return 0, ErrRBIdentity
+	}
+	return rbIdentity - 1, nil
+}
+
+func NewNEA3(key []byte, count uint32, bearer uint32, direction zuc.KeyDirection) *NEA3 {
+	return &NEA3{
+		eea3: eea3.NewEEA3(key, count, bearer&0x1f, direction&1),
+	}
+}
+
+func (n *NEA3) Encrypt(m []byte, blength uint32) []byte {
+	return n.eea3.Encrypt(m, blength)
+}
+
+func (n *NEA3) Decrypt(m []byte, blength uint32) []byte {
+	return n.eea3.Decrypt(m, blength)
+}
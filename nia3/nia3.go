@@ -0,0 +1,27 @@
+// 128-NIA3 as specified in 3GPP TS 33.501 Annex D.3.
+// 128-NIA3 is identical to 128-EIA3, the 5-bit BEARER being derived from the NR radio bearer identity.
+
+package nia3
+
+import (
+	"github.com/frankurcrazy/zuc"
+	"github.com/frankurcrazy/zuc/eia3"
+)
+
+type NIA3 struct {
+	eia3 *eia3.EIA3
+}
+
+func NewNIA3(key []byte, count uint32, bearer uint32, direction zuc.KeyDirection) *NIA3 {
+	return &NIA3{
+		eia3: eia3.NewEIA3(key, count, bearer&0x1f, direction&1),
+	}
+}
+
+func (n *NIA3) Hash(m []byte, blen uint32) []byte {
+	return n.eia3.Hash(m, blen)
+}
+
+func (n *NIA3) Verify(m []byte, blen uint32, mac []byte) bool {
+	return n.eia3.Verify(m, blen, mac)
+}
@@ -0,0 +1,114 @@
+package pdcp
+
+// Control PDU types, TS 36.323 clause 6.3.8 and TS 38.323 clause 6.3.8.
+const (
+	PDUTypeStatusReport = uint8(0)
+	PDUTypeROHCFeedback = uint8(1)
+)
+
+// ControlPDU is a PDCP status report or interspersed ROHC feedback. For NR status reports FMC
+// holds the First Missing COUNT, for LTE the First Missing SN (FMS).
+type ControlPDU struct {
+	Type     uint8
+	FMC      uint32
+	Bitmap   []byte
+	Feedback []byte
+}
+
+// statusHeaderLength returns the length of the status report fields preceding the bitmap.
+func (c *Config) statusHeaderLength() (int, error) {
+	switch {
+	case c.RAT == NR:
+		return 5, nil
+	case c.RAT == LTE && c.SNLength == 12:
+		return 2, nil
+	case c.RAT == LTE && c.SNLength == 15:
+		return 3, nil
+	}
+
+	return 0, ErrSNLength
+}
+
+func (c *Config) BuildControlPDU(ctrl ControlPDU) ([]byte, error) {
+	if c.SRB {
+		return nil, ErrPDUType
+	}
+
+	switch ctrl.Type {
+	case PDUTypeROHCFeedback:
+		pdu := []byte{ctrl.Type << 4}
+
+		return append(pdu, ctrl.Feedback...), nil
+	case PDUTypeStatusReport:
+		hlen, err := c.statusHeaderLength()
+		if err != nil {
+			return nil, err
+		}
+
+		pdu := make([]byte, hlen, hlen+len(ctrl.Bitmap))
+		pdu[0] = ctrl.Type << 4
+
+		switch {
+		case c.RAT == NR:
+			pdu[1] = uint8(ctrl.FMC >> 24)
+			pdu[2] = uint8(ctrl.FMC >> 16)
+			pdu[3] = uint8(ctrl.FMC >> 8)
+			pdu[4] = uint8(ctrl.FMC)
+		case c.SNLength == 12:
+			pdu[0] |= uint8(ctrl.FMC>>8) & 0x0f
+			pdu[1] = uint8(ctrl.FMC)
+		default:
+			pdu[1] = uint8(ctrl.FMC>>8) & 0x7f
+			pdu[2] = uint8(ctrl.FMC)
+		}
+
+		return append(pdu, ctrl.Bitmap...), nil
+	}
+
+	return nil, ErrPDUType
+}
+
+func (c *Config) ParseControlPDU(pdu []byte) (ControlPDU, error) {
+	ctrl := ControlPDU{}
+
+	if len(pdu) < 1 {
+		return ctrl, ErrPDUTooShort
+	}
+
+	if c.SRB || pdu[0]&0x80 != 0 {
+		return ctrl, ErrNotControlPDU
+	}
+
+	ctrl.Type = (pdu[0] >> 4) & 0x07
+
+	switch ctrl.Type {
+	case PDUTypeROHCFeedback:
+		ctrl.Feedback = append([]byte{}, pdu[1:]...)
+
+		return ctrl, nil
+	case PDUTypeStatusReport:
+		hlen, err := c.statusHeaderLength()
+		if err != nil {
+			return ctrl, err
+		}
+
+		if len(pdu) < hlen {
+			return ctrl, ErrPDUTooShort
+		}
+
+		switch {
+		case c.RAT == NR:
+			ctrl.FMC = uint32(pdu[1])<<24 | uint32(pdu[2])<<16 | uint32(pdu[3])<<8 | uint32(pdu[4])
+		case c.SNLength == 12:
+			ctrl.FMC = uint32(pdu[0]&0x0f)<<8 | uint32(pdu[1])
+		default:
+			ctrl.FMC = uint32(pdu[1]&0x7f)<<8 | uint32(pdu[2])
+		}
+
+		ctrl.Bitmap = append([]byte{}, pdu[hlen:]...)
+
+		return ctrl, nil
+	}
+
+	return ctrl, ErrPDUType
+}
@@ -0,0 +1,192 @@
+package pdcp
+
+import (
+	"errors"
+)
+
+// DefaultMaxFailures is the number of consecutive integrity failures after which a CountEstimator
+// reports an HFN desynchronization.
+const DefaultMaxFailures = 3
+
+var (
+	ErrOutsideWindow = errors.New("pdcp: COUNT outside the reception window")
+	ErrDuplicate     = errors.New("pdcp: duplicate PDU")
+	ErrHFNDesync     = errors.New("pdcp: repeated MAC-I failures, HFN desynchronized")
+)
+
+// CountEstimator determines the COUNT of received PDUs from their SN, following the receive
+// operation of TS 38.323 clause 5.2.2: the HFN is taken relative to RX_DELIV within a window of
+// half the SN space. It tracks RX_DELIV and RX_NEXT as PDUs are accepted.
+type CountEstimator struct {
+	snLength    int
+	maxFailures int
+
+	rxDeliv  uint32
+	rxNext   uint32
+	received map[uint32]struct{}
+	failures int
+}
+
+// NewCountEstimator returns an estimator for snLength bit SNs. maxFailures consecutive integrity
+// failures flag an HFN desync; DefaultMaxFailures is used when it is not positive.
+func NewCountEstimator(snLength int, maxFailures int) *CountEstimator {
+	if maxFailures <= 0 {
+		maxFailures = DefaultMaxFailures
+	}
+
+	return &CountEstimator{
+		snLength:    snLength,
+		maxFailures: maxFailures,
+		received:    map[uint32]struct{}{},
+	}
+}
+
+// RxDeliv is the COUNT of the first PDU not yet delivered to upper layers.
+func (e *CountEstimator) RxDeliv() uint32 {
+	return e.rxDeliv
+}
+
+// RxNext is the COUNT following that of the highest received PDU.
+func (e *CountEstimator) RxNext() uint32 {
+	return e.rxNext
+}
+
+// SetState sets RX_DELIV and RX_NEXT, e.g. on re-establishment, and clears the failure count.
+func (e *CountEstimator) SetState(rxDeliv uint32, rxNext uint32) {
+	e.rxDeliv = rxDeliv
+	e.rxNext = rxNext
+	e.received = map[uint32]struct{}{}
+	e.failures = 0
+}
+
+func (e *CountEstimator) windowSize() uint32 {
+	return 1 << uint(e.snLength-1)
+}
+
+// Desynchronized reports whether the last maxFailures verifications all failed.
+func (e *CountEstimator) Desynchronized() bool {
+	return e.failures >= e.maxFailures
+}
+
+// Estimate returns RCVD_COUNT for a received SN.
+func (e *CountEstimator) Estimate(sn uint32) (uint32, error) {
+	sn &= 1<<uint(e.snLength) - 1
+	window := e.windowSize()
+
+	hfn := HFN(e.rxDeliv, e.snLength)
+	delivSN := SN(e.rxDeliv, e.snLength)
+
+	switch {
+	case delivSN >= window && sn < delivSN-window:
+		if hfn == HFN(^uint32(0), e.snLength) {
+			return 0, ErrOutsideWindow
+		}
+
+		hfn += 1
+	case sn >= delivSN+window:
+		if hfn == 0 {
+			return 0, ErrOutsideWindow
+		}
+
+		hfn -= 1
+	}
+
+	return Count(hfn, sn, e.snLength), nil
+}
+
+// Accept records that the PDU with count passed integrity verification, discarding PDUs below
+// RX_DELIV or already received, and updates RX_NEXT and RX_DELIV.
+func (e *CountEstimator) Accept(count uint32) error {
+	e.failures = 0
+
+	if count < e.rxDeliv {
+		return ErrDuplicate
+	}
+
+	if _, ok := e.received[count]; ok {
+		return ErrDuplicate
+	}
+
+	if count >= e.rxNext {
+		e.rxNext = count + 1
+	}
+
+	e.received[count] = struct{}{}
+	e.deliver()
+
+	return nil
+}
+
+// Fail records an integrity failure, returning ErrHFNDesync once maxFailures consecutive
+// failures have been seen and ErrIntegrityFailed before that.
+func (e *CountEstimator) Fail() error {
+	e.failures += 1
+
+	if e.Desynchronized() {
+		return ErrHFNDesync
+	}
+
+	return ErrIntegrityFailed
+}
+
+// ReorderingTimeout handles t-Reordering expiry, moving RX_DELIV to the first COUNT not yet
+// received at or after rxReord.
+func (e *CountEstimator) ReorderingTimeout(rxReord uint32) {
+	for count := range e.received {
+		if count < rxReord {
+			delete(e.received, count)
+		}
+	}
+
+	if rxReord > e.rxDeliv {
+		e.rxDeliv = rxReord
+	}
+
+	e.deliver()
+}
+
+func (e *CountEstimator) deliver() {
+	for {
+		if _, ok := e.received[e.rxDeliv]; !ok {
+			return
+		}
+
+		delete(e.received, e.rxDeliv)
+		e.rxDeliv += 1
+	}
+}
+
+// Unprotect estimates COUNT for a received data PDU, deciphers and verifies it, and updates the
+// reception state. Integrity failures leave RX_DELIV and RX_NEXT unchanged.
+func (e *CountEstimator) Unprotect(c *Config, pdu []byte) ([]byte, uint32, error) {
+	if err := c.Validate(); err != nil {
+		return nil, 0, err
+	}
+
+	sn, err := c.ParseHeader(pdu)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	count, err := e.Estimate(sn)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if count < e.rxDeliv {
+		return nil, count, ErrDuplicate
+	}
+
+	sdu, err := UnprotectCount(c, count, pdu)
+	if err == ErrIntegrityFailed {
+		return nil, count, e.Fail()
+	} else if err != nil {
+		return nil, count, err
+	}
+
+	if err := e.Accept(count); err != nil {
+		return nil, count, err
+	}
+
+	return sdu, count, nil
+}
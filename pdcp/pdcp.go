@@ -0,0 +1,256 @@
+// PDCP data and control PDU handling for LTE (3GPP TS 36.323) and NR (3GPP TS 38.323), with
+// integrity protection by 128-EIA3/NIA3 and ciphering by 128-EEA3/NEA3.
+
+package pdcp
+
+import (
+	"crypto/subtle"
+	"errors"
+	"github.com/frankurcrazy/zuc"
+	"github.com/frankurcrazy/zuc/eea3"
+	"github.com/frankurcrazy/zuc/eia3"
+)
+
+type RAT int
+
+const (
+	LTE = RAT(iota)
+	NR
+)
+
+const (
+	MACSize = 4
+	KeySize = 16
+
+	// Maximum PDCP SDU sizes, TS 36.323 clause 4.3.1 and TS 38.323 clause 4.3.1. NR SDUs go beyond the
+	// MaxLength of eea3 and eia3, so PDUs are checked against these instead.
+	MaxSDUSizeLTE = 8188
+	MaxSDUSizeNR  = 9000
+)
+
+var (
+	ErrSNLength        = errors.New("pdcp: SN length not supported for this RAT and bearer type")
+	ErrRBIdentity      = errors.New("pdcp: RB identity out of range")
+	ErrKeySize         = errors.New("pdcp: keys must be 16 bytes")
+	ErrPDUTooShort     = errors.New("pdcp: PDU too short")
+	ErrSDUTooLong      = errors.New("pdcp: SDU exceeds maximum size")
+	ErrNotDataPDU      = errors.New("pdcp: not a data PDU")
+	ErrNotControlPDU   = errors.New("pdcp: not a control PDU")
+	ErrPDUType         = errors.New("pdcp: unsupported control PDU type")
+	ErrIntegrityFailed = errors.New("pdcp: MAC-I verification failed")
+)
+
+// Config describes one PDCP entity. A nil CipherKey selects the null ciphering algorithm and a nil
+// IntegrityKey the null integrity algorithm, whose MAC-I is all zeros and is not checked.
+type Config struct {
+	RAT        RAT
+	SNLength   int
+	SRB        bool
+	Integrity  bool
+	RBIdentity uint32
+	Direction  zuc.KeyDirection
+
+	CipherKey    []byte
+	IntegrityKey []byte
+}
+
+func (c *Config) Validate() error {
+	switch {
+	case c.RAT == LTE && c.SRB:
+		if c.SNLength != 5 {
+			return ErrSNLength
+		}
+	case c.RAT == LTE:
+		if c.SNLength != 7 && c.SNLength != 12 && c.SNLength != 15 {
+			return ErrSNLength
+		}
+	case c.RAT == NR && c.SRB:
+		if c.SNLength != 12 {
+			return ErrSNLength
+		}
+	case c.RAT == NR:
+		if c.SNLength != 12 && c.SNLength != 18 {
+			return ErrSNLength
+		}
+	default:
+		return ErrSNLength
+	}
+
+	if c.RBIdentity < 1 || c.RBIdentity > 32 {
+		return ErrRBIdentity
+	}
+
+	if c.CipherKey != nil && len(c.CipherKey) != KeySize {
+		return ErrKeySize
+	}
+
+	if c.IntegrityKey != nil && len(c.IntegrityKey) != KeySize {
+		return ErrKeySize
+	}
+
+	return nil
+}
+
+// Bearer is the 5-bit BEARER input of the security algorithms, RB identity - 1.
+func (c *Config) Bearer() uint32 {
+	return (c.RBIdentity - 1) & 0x1f
+}
+
+// HasMACI reports whether data PDUs carry MAC-I: always on SRBs, on DRBs when integrity protection is configured.
+func (c *Config) HasMACI() bool {
+	return c.SRB || c.Integrity
+}
+
+func (c *Config) MaxSDUSize() int {
+	if c.RAT == NR {
+		return MaxSDUSizeNR
+	}
+
+	return MaxSDUSizeLTE
+}
+
+func (c *Config) HeaderLength() int {
+	return (c.SNLength + 7) / 8
+}
+
+func (c *Config) snMask() uint32 {
+	return 1<<uint(c.SNLength) - 1
+}
+
+// Count builds COUNT from HFN and SN.
+func Count(hfn uint32, sn uint32, snLength int) uint32 {
+	return hfn<<uint(snLength) | sn&(1<<uint(snLength)-1)
+}
+
+// HFN and SN split COUNT for a given SN length.
+func HFN(count uint32, snLength int) uint32 {
+	return count >> uint(snLength)
+}
+
+func SN(count uint32, snLength int) uint32 {
+	return count & (1<<uint(snLength) - 1)
+}
+
+// BuildHeader returns the data PDU header carrying sn. DRB headers set the D/C bit, all R bits are 0.
+func (c *Config) BuildHeader(sn uint32) []byte {
+	sn &= c.snMask()
+
+	header := make([]byte, c.HeaderLength())
+	for i := range header {
+		header[i] = uint8(sn >> (8 * uint(len(header)-1-i)))
+	}
+
+	if !c.SRB {
+		header[0] |= 0x80
+	}
+
+	return header
+}
+
+// ParseHeader returns the SN of a data PDU.
+func (c *Config) ParseHeader(pdu []byte) (uint32, error) {
+	if len(pdu) < c.HeaderLength() {
+		return 0, ErrPDUTooShort
+	}
+
+	if !c.SRB && pdu[0]&0x80 == 0 {
+		return 0, ErrNotDataPDU
+	}
+
+	sn := uint32(0)
+	for _, b := range pdu[:c.HeaderLength()] {
+		sn = sn<<8 | uint32(b)
+	}
+
+	return sn & c.snMask(), nil
+}
+
+func (c *Config) mac(count uint32, m []byte) []byte {
+	if c.IntegrityKey == nil {
+		return make([]byte, MACSize)
+	}
+
+	return eia3.NewEIA3(c.IntegrityKey, count, c.Bearer(), c.Direction).Hash(m, uint32(len(m))*8)
+}
+
+func (c *Config) cipher(count uint32, m []byte) {
+	if c.CipherKey == nil {
+		return
+	}
+
+	copy(m, eea3.NewEEA3(c.CipherKey, count, c.Bearer(), c.Direction).Encrypt(m, uint32(len(m))*8))
+}
+
+// Protect builds the data PDU for sdu with the given COUNT. MAC-I, when present, is computed over
+// the header and the SDU; the SDU and MAC-I are then ciphered, the header is not.
+func Protect(c *Config, count uint32, sdu []byte) ([]byte, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	if len(sdu) > c.MaxSDUSize() {
+		return nil, ErrSDUTooLong
+	}
+
+	pdu := c.BuildHeader(SN(count, c.SNLength))
+	hlen := len(pdu)
+
+	pdu = append(pdu, sdu...)
+	if c.HasMACI() {
+		pdu = append(pdu, c.mac(count, pdu)...)
+	}
+
+	c.cipher(count, pdu[hlen:])
+
+	return pdu, nil
+}
+
+// Unprotect deciphers and verifies a data PDU, rebuilding COUNT from hfn and the SN in its header.
+// It returns the SDU and the COUNT it was processed with.
+func Unprotect(c *Config, hfn uint32, pdu []byte) ([]byte, uint32, error) {
+	if err := c.Validate(); err != nil {
+		return nil, 0, err
+	}
+
+	sn, err := c.ParseHeader(pdu)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	count := Count(hfn, sn, c.SNLength)
+	sdu, err := UnprotectCount(c, count, pdu)
+
+	return sdu, count, err
+}
+
+// UnprotectCount deciphers and verifies a data PDU with an already determined COUNT.
+func UnprotectCount(c *Config, count uint32, pdu []byte) ([]byte, error) {
+	hlen := c.HeaderLength()
+
+	minLength := hlen
+	if c.HasMACI() {
+		minLength += MACSize
+	}
+
+	if len(pdu) < minLength {
+		return nil, ErrPDUTooShort
+	}
+
+	if len(pdu)-minLength > c.MaxSDUSize() {
+		return nil, ErrSDUTooLong
+	}
+
+	plain := append([]byte{}, pdu...)
+	c.cipher(count, plain[hlen:])
+
+	if !c.HasMACI() {
+		return plain[hlen:], nil
+	}
+
+	body := plain[:len(plain)-MACSize]
+	if c.IntegrityKey != nil && subtle.ConstantTimeCompare(c.mac(count, body), plain[len(body):]) != 1 {
+		return nil, ErrIntegrityFailed
+	}
+
+	return body[hlen:], nil
+}
@@ -0,0 +1,333 @@
+package pdcp
+
+import (
+	"encoding/hex"
+	"github.com/frankurcrazy/zuc"
+	"github.com/frankurcrazy/zuc/eea3"
+	"github.com/frankurcrazy/zuc/eia3"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var (
+	ck, _ = hex.DecodeString("173d14ba5003731d7a60049470f00a29")
+	ik, _ = hex.DecodeString("c9e6cec4607c72db000aefa88385ab0a")
+)
+
+func TestValidate(t *testing.T) {
+	type TestSet struct {
+		Config Config
+		Err    error
+	}
+
+	testSets := map[string]TestSet{
+		"LTE SRB":        {Config{RAT: LTE, SRB: true, SNLength: 5, RBIdentity: 1}, nil},
+		"LTE SRB 12":     {Config{RAT: LTE, SRB: true, SNLength: 12, RBIdentity: 1}, ErrSNLength},
+		"LTE DRB 7":      {Config{RAT: LTE, SNLength: 7, RBIdentity: 3}, nil},
+		"LTE DRB 18":     {Config{RAT: LTE, SNLength: 18, RBIdentity: 3}, ErrSNLength},
+		"NR SRB":         {Config{RAT: NR, SRB: true, SNLength: 12, RBIdentity: 2}, nil},
+		"NR DRB 18":      {Config{RAT: NR, SNLength: 18, RBIdentity: 32}, nil},
+		"NR DRB 15":      {Config{RAT: NR, SNLength: 15, RBIdentity: 4}, ErrSNLength},
+		"RB identity 0":  {Config{RAT: NR, SNLength: 18, RBIdentity: 0}, ErrRBIdentity},
+		"RB identity 33": {Config{RAT: NR, SNLength: 18, RBIdentity: 33}, ErrRBIdentity},
+		"short key":      {Config{RAT: NR, SNLength: 18, RBIdentity: 1, CipherKey: ck[:8]}, ErrKeySize},
+	}
+
+	for name, set := range testSets {
+		assert.Equal(t, set.Err, set.Config.Validate(), name)
+	}
+}
+
+func TestHeader(t *testing.T) {
+	type TestSet struct {
+		Config Config
+		SN     uint32
+		Header string
+	}
+
+	testSets := map[string]TestSet{
+		"LTE SRB":    {Config{RAT: LTE, SRB: true, SNLength: 5}, 0x1b, "1b"},
+		"LTE DRB 7":  {Config{RAT: LTE, SNLength: 7}, 0x55, "d5"},
+		"LTE DRB 12": {Config{RAT: LTE, SNLength: 12}, 0xabc, "8abc"},
+		"LTE DRB 15": {Config{RAT: LTE, SNLength: 15}, 0x7abc, "fabc"},
+		"NR SRB":     {Config{RAT: NR, SRB: true, SNLength: 12}, 0xabc, "0abc"},
+		"NR DRB 12":  {Config{RAT: NR, SNLength: 12}, 0x123, "8123"},
+		"NR DRB 18":  {Config{RAT: NR, SNLength: 18}, 0x3abcd, "83abcd"},
+	}
+
+	for name, set := range testSets {
+		header := set.Config.BuildHeader(set.SN)
+		assert.Equal(t, set.Header, hex.EncodeToString(header), name)
+
+		sn, err := set.Config.ParseHeader(header)
+		assert.Nil(t, err, name)
+		assert.Equal(t, set.SN, sn, name)
+	}
+
+	c := Config{RAT: NR, SNLength: 18}
+	_, err := c.ParseHeader([]byte{0x03, 0xab, 0xcd})
+	assert.Equal(t, ErrNotDataPDU, err)
+
+	_, err = c.ParseHeader([]byte{0x83, 0xab})
+	assert.Equal(t, ErrPDUTooShort, err)
+}
+
+func TestCount(t *testing.T) {
+	count := Count(0x1234, 0x3abcd, 18)
+	assert.Equal(t, uint32(0x1234<<18|0x3abcd), count)
+	assert.Equal(t, uint32(0x1234), HFN(count, 18))
+	assert.Equal(t, uint32(0x3abcd), SN(count, 18))
+}
+
+func TestProtect(t *testing.T) {
+	c := &Config{
+		RAT:          NR,
+		SNLength:     12,
+		SRB:          true,
+		RBIdentity:   1,
+		Direction:    zuc.KEY_UPLINK,
+		CipherKey:    ck,
+		IntegrityKey: ik,
+	}
+	sdu := []byte("RRCSetupComplete")
+	count := uint32(0x5a0123)
+
+	pdu, err := Protect(c, count, sdu)
+	assert.Nil(t, err)
+
+	// Compose the same PDU from the algorithms directly.
+	header := []byte{0x01, 0x23}
+	mac := eia3.NewEIA3(ik, count, 0, zuc.KEY_UPLINK).Hash(append(header, sdu...), uint32(len(header)+len(sdu))*8)
+	body := append(append([]byte{}, sdu...), mac...)
+	expected := append(header, eea3.NewEEA3(ck, count, 0, zuc.KEY_UPLINK).Encrypt(body, uint32(len(body))*8)...)
+	assert.Equal(t, expected, pdu)
+
+	out, rxCount, err := Unprotect(c, HFN(count, 12), pdu)
+	assert.Nil(t, err)
+	assert.Equal(t, count, rxCount)
+	assert.Equal(t, sdu, out)
+
+	for i := range pdu {
+		tampered := append([]byte{}, pdu...)
+		tampered[i] ^= 0x01
+		_, _, err = Unprotect(c, HFN(count, 12), tampered)
+		assert.Equal(t, ErrIntegrityFailed, err, i)
+	}
+
+	_, _, err = Unprotect(c, HFN(count, 12)+1, pdu)
+	assert.Equal(t, ErrIntegrityFailed, err)
+
+	_, _, err = Unprotect(c, 0, pdu[:5])
+	assert.Equal(t, ErrPDUTooShort, err)
+}
+
+func TestProtectRoundTrip(t *testing.T) {
+	type TestSet struct {
+		Config Config
+		Count  uint32
+		Length int
+	}
+
+	testSets := map[string]TestSet{
+		"LTE SRB":                {Config{RAT: LTE, SRB: true, SNLength: 5, RBIdentity: 2, CipherKey: ck, IntegrityKey: ik}, 0x3f, 20},
+		"LTE DRB 7":              {Config{RAT: LTE, SNLength: 7, RBIdentity: 3, CipherKey: ck}, 0x1ff, 40},
+		"LTE DRB 15":             {Config{RAT: LTE, SNLength: 15, RBIdentity: 5, Direction: zuc.KEY_DOWNLINK, CipherKey: ck}, 0x12345, 1500},
+		"NR DRB 18":              {Config{RAT: NR, SNLength: 18, RBIdentity: 4, CipherKey: ck}, 0xfedcba, 9000},
+		"NR DRB 18 integrity":    {Config{RAT: NR, SNLength: 18, RBIdentity: 4, Integrity: true, CipherKey: ck, IntegrityKey: ik}, 0xfedcba, 1500},
+		"NR DRB null algorithms": {Config{RAT: NR, SNLength: 12, RBIdentity: 1, Integrity: true}, 0x1001, 10},
+	}
+
+	for name, set := range testSets {
+		sdu := make([]byte, set.Length)
+		for i := range sdu {
+			sdu[i] = uint8(i)
+		}
+
+		pdu, err := Protect(&set.Config, set.Count, sdu)
+		assert.Nil(t, err, name)
+
+		expected := set.Config.HeaderLength() + set.Length
+		if set.Config.HasMACI() {
+			expected += MACSize
+		}
+		assert.Equal(t, expected, len(pdu), name)
+
+		out, count, err := Unprotect(&set.Config, HFN(set.Count, set.Config.SNLength), pdu)
+		assert.Nil(t, err, name)
+		assert.Equal(t, set.Count, count, name)
+		assert.Equal(t, sdu, out, name)
+	}
+}
+
+func TestControlPDU(t *testing.T) {
+	type TestSet struct {
+		Config Config
+		PDU    ControlPDU
+		Bytes  string
+	}
+
+	testSets := map[string]TestSet{
+		"NR status report":     {Config{RAT: NR, SNLength: 18}, ControlPDU{Type: PDUTypeStatusReport, FMC: 0x01020304, Bitmap: []byte{0xa5}}, "0001020304a5"},
+		"LTE 12 status report": {Config{RAT: LTE, SNLength: 12}, ControlPDU{Type: PDUTypeStatusReport, FMC: 0xabc, Bitmap: []byte{0xff, 0x01}}, "0abcff01"},
+		"LTE 15 status report": {Config{RAT: LTE, SNLength: 15}, ControlPDU{Type: PDUTypeStatusReport, FMC: 0x7abc, Bitmap: []byte{}}, "007abc"},
+		"ROHC feedback":        {Config{RAT: NR, SNLength: 12}, ControlPDU{Type: PDUTypeROHCFeedback, Feedback: []byte{0xf4, 0x01}}, "10f401"},
+	}
+
+	for name, set := range testSets {
+		pdu, err := set.Config.BuildControlPDU(set.PDU)
+		assert.Nil(t, err, name)
+		assert.Equal(t, set.Bytes, hex.EncodeToString(pdu), name)
+
+		ctrl, err := set.Config.ParseControlPDU(pdu)
+		assert.Nil(t, err, name)
+		assert.Equal(t, set.PDU.Type, ctrl.Type, name)
+		assert.Equal(t, set.PDU.FMC, ctrl.FMC, name)
+		assert.Equal(t, len(set.PDU.Bitmap), len(ctrl.Bitmap), name)
+		assert.Equal(t, len(set.PDU.Feedback), len(ctrl.Feedback), name)
+	}
+
+	c := Config{RAT: NR, SNLength: 18}
+	_, err := c.ParseControlPDU([]byte{0x80, 0x00, 0x01})
+	assert.Equal(t, ErrNotControlPDU, err)
+
+	_, err = c.ParseControlPDU([]byte{0x00, 0x01})
+	assert.Equal(t, ErrPDUTooShort, err)
+
+	_, err = c.ParseControlPDU([]byte{0x70})
+	assert.Equal(t, ErrPDUType, err)
+
+	_, err = c.BuildControlPDU(ControlPDU{Type: 5})
+	assert.Equal(t, ErrPDUType, err)
+
+	srb := Config{RAT: NR, SRB: true, SNLength: 12}
+	_, err = srb.BuildControlPDU(ControlPDU{})
+	assert.Equal(t, ErrPDUType, err)
+
+	lte7 := Config{RAT: LTE, SNLength: 7}
+	_, err = lte7.BuildControlPDU(ControlPDU{})
+	assert.Equal(t, ErrSNLength, err)
+}
+
+func TestEstimate(t *testing.T) {
+	type TestSet struct {
+		RxDeliv uint32
+		SN      uint32
+		Count   uint32
+		Err     error
+	}
+
+	// 12-bit SN, window of 2048.
+	testSets := map[string]TestSet{
+		"same HFN":           {Count(5, 100, 12), 200, Count(5, 200, 12), nil},
+		"late PDU":           {Count(5, 100, 12), 50, Count(5, 50, 12), nil},
+		"wraparound":         {Count(5, 4000, 12), 10, Count(6, 10, 12), nil},
+		"previous HFN":       {Count(5, 100, 12), 4000, Count(4, 4000, 12), nil},
+		"window edge":        {Count(5, 3000, 12), 952, Count(5, 952, 12), nil},
+		"below zero":         {Count(0, 100, 12), 4000, 0, ErrOutsideWindow},
+		"upper window edge":  {Count(5, 100, 12), 2148, Count(4, 2148, 12), nil},
+		"inside upper edge":  {Count(5, 100, 12), 2147, Count(5, 2147, 12), nil},
+		"next HFN at window": {Count(5, 3000, 12), 951, Count(6, 951, 12), nil},
+	}
+
+	for name, set := range testSets {
+		e := NewCountEstimator(12, 0)
+		e.SetState(set.RxDeliv, set.RxDeliv)
+
+		count, err := e.Estimate(set.SN)
+		assert.Equal(t, set.Err, err, name)
+		assert.Equal(t, set.Count, count, name)
+	}
+}
+
+func TestCountEstimator(t *testing.T) {
+	c := &Config{RAT: NR, SNLength: 12, RBIdentity: 2, Integrity: true, CipherKey: ck, IntegrityKey: ik}
+
+	start := Count(7, 4090, 12)
+	pdus := [][]byte{}
+	for i := uint32(0); i < 12; i += 1 {
+		pdu, err := Protect(c, start+i, []byte{uint8(i)})
+		assert.Nil(t, err)
+		pdus = append(pdus, pdu)
+	}
+
+	e := NewCountEstimator(12, 0)
+	e.SetState(start, start)
+
+	// Out of order across the SN wraparound.
+	for _, i := range []int{0, 2, 1, 5, 3} {
+		sdu, count, err := e.Unprotect(c, pdus[i])
+		assert.Nil(t, err, i)
+		assert.Equal(t, start+uint32(i), count, i)
+		assert.Equal(t, []byte{uint8(i)}, sdu, i)
+	}
+
+	assert.Equal(t, start+4, e.RxDeliv())
+	assert.Equal(t, start+6, e.RxNext())
+
+	_, _, err := e.Unprotect(c, pdus[5])
+	assert.Equal(t, ErrDuplicate, err)
+
+	_, _, err = e.Unprotect(c, pdus[1])
+	assert.Equal(t, ErrDuplicate, err)
+
+	// PDU 4 is lost, t-Reordering expires.
+	e.ReorderingTimeout(start + 6)
+	assert.Equal(t, start+6, e.RxDeliv())
+
+	_, _, err = e.Unprotect(c, pdus[4])
+	assert.Equal(t, ErrDuplicate, err)
+
+	_, _, err = e.Unprotect(c, pdus[6])
+	assert.Nil(t, err)
+	assert.Equal(t, start+7, e.RxDeliv())
+}
+
+func TestHFNDesync(t *testing.T) {
+	c := &Config{RAT: NR, SNLength: 12, SRB: true, RBIdentity: 1, CipherKey: ck, IntegrityKey: ik}
+
+	// The receiver is one HFN behind the sender.
+	e := NewCountEstimator(12, 2)
+	e.SetState(Count(2, 0, 12), Count(2, 0, 12))
+
+	for i := uint32(0); i < 3; i += 1 {
+		pdu, _ := Protect(c, Count(3, i, 12), []byte{0x00})
+		_, _, err := e.Unprotect(c, pdu)
+
+		if i == 0 {
+			assert.Equal(t, ErrIntegrityFailed, err)
+			assert.False(t, e.Desynchronized())
+		} else {
+			assert.Equal(t, ErrHFNDesync, err)
+			assert.True(t, e.Desynchronized())
+		}
+	}
+
+	assert.Equal(t, Count(2, 0, 12), e.RxDeliv())
+
+	e.SetState(Count(3, 3, 12), Count(3, 3, 12))
+	assert.False(t, e.Desynchronized())
+
+	pdu, _ := Protect(c, Count(3, 3, 12), []byte{0x00})
+	_, count, err := e.Unprotect(c, pdu)
+	assert.Nil(t, err)
+	assert.Equal(t, Count(3, 3, 12), count)
+}
+
+func TestSDUSize(t *testing.T) {
+	lte := &Config{RAT: LTE, SRB: true, SNLength: 5, RBIdentity: 1, CipherKey: ck, IntegrityKey: ik}
+	nr := &Config{RAT: NR, SNLength: 18, RBIdentity: 4, Integrity: true, CipherKey: ck, IntegrityKey: ik}
+
+	for _, c := range []*Config{lte, nr} {
+		pdu, err := Protect(c, 1, make([]byte, c.MaxSDUSize()))
+		assert.Nil(t, err, c.RAT)
+
+		_, _, err = Unprotect(c, 0, pdu)
+		assert.Nil(t, err, c.RAT)
+
+		_, err = Protect(c, 1, make([]byte, c.MaxSDUSize()+1))
+		assert.Equal(t, ErrSDUTooLong, err, c.RAT)
+
+		_, _, err = Unprotect(c, 0, append(pdu, 0))
+		assert.Equal(t, ErrSDUTooLong, err, c.RAT)
+	}
+}
@@ -0,0 +1,241 @@
+// Security context tying keys, selected algorithms and per-bearer COUNT state together.
+// Protection follows the PDCP order of TS 36.323 / TS 38.323: MAC-I is computed over the PDU,
+// then the PDU and MAC-I are ciphered.
+
+package security
+
+import (
+	"crypto/subtle"
+	"errors"
+	"github.com/frankurcrazy/zuc"
+	"github.com/frankurcrazy/zuc/algorithm"
+	"github.com/frankurcrazy/zuc/eea3"
+	"github.com/frankurcrazy/zuc/eia3"
+	"sync"
+)
+
+// Algorithm identities, TS 33.401 Annex B and TS 33.501 Annex D. Any algorithm registered with
+// the algorithm package can be selected.
+const (
+	EEA0 = algorithm.EEA0
+	EEA3 = algorithm.EEA3
+	EIA0 = algorithm.EIA0
+	EIA3 = algorithm.EIA3
+)
+
+const (
+	KeySize    = 16
+	MACSize    = 4
+	MaxBearers = 32
+)
+
+var (
+	ErrKeySize         = errors.New("security: keys must be 16 bytes")
+	ErrAlgorithm       = errors.New("security: unsupported algorithm")
+	ErrBearer          = errors.New("security: BEARER must fit in 5 bits")
+	ErrCountExhausted  = errors.New("security: COUNT exhausted, rekeying required")
+	ErrPDUTooShort     = errors.New("security: PDU is shorter than MAC-I")
+	ErrIntegrityFailed = errors.New("security: MAC-I verification failed")
+)
+
+type bearerState struct {
+	sync.Mutex
+	count     [2]uint32
+	exhausted [2]bool
+}
+
+// Context protects and unprotects PDUs for up to 32 bearers. Each bearer keeps separate uplink and
+// downlink COUNTs; operations on different bearers proceed concurrently.
+type Context struct {
+	cipherKey    []byte
+	integrityKey []byte
+	cipherAlg    uint8
+	integrityAlg uint8
+
+	mu      sync.Mutex
+	bearers map[uint32]*bearerState
+}
+
+func NewContext(cipherKey []byte, integrityKey []byte, cipherAlg uint8, integrityAlg uint8) (*Context, error) {
+	if len(cipherKey) != KeySize || len(integrityKey) != KeySize {
+		return nil, ErrKeySize
+	}
+
+	if !algorithm.HasCipher(cipherAlg) || !algorithm.HasIntegrity(integrityAlg) {
+		return nil, ErrAlgorithm
+	}
+
+	c := &Context{
+		cipherKey:    append([]byte{}, cipherKey...),
+		integrityKey: append([]byte{}, integrityKey...),
+		cipherAlg:    cipherAlg,
+		integrityAlg: integrityAlg,
+		bearers:      map[uint32]*bearerState{},
+	}
+
+	return c, nil
+}
+
+func (c *Context) bearer(bearer uint32) (*bearerState, error) {
+	if bearer >= MaxBearers {
+		return nil, ErrBearer
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	b, ok := c.bearers[bearer]
+	if !ok {
+		b = &bearerState{}
+		c.bearers[bearer] = b
+	}
+
+	return b, nil
+}
+
+// Count returns the next COUNT to be used for bearer in direction dir.
+func (c *Context) Count(bearer uint32, dir zuc.KeyDirection) (uint32, error) {
+	b, err := c.bearer(bearer)
+	if err != nil {
+		return 0, err
+	}
+
+	b.Lock()
+	defer b.Unlock()
+
+	return b.count[dir&1], nil
+}
+
+// SetCount sets the next COUNT to be used for bearer in direction dir, e.g. after re-establishment.
+func (c *Context) SetCount(bearer uint32, dir zuc.KeyDirection, count uint32) error {
+	b, err := c.bearer(bearer)
+	if err != nil {
+		return err
+	}
+
+	b.Lock()
+	defer b.Unlock()
+
+	b.count[dir&1] = count
+	b.exhausted[dir&1] = false
+
+	return nil
+}
+
+func (c *Context) mac(count uint32, bearer uint32, dir zuc.KeyDirection, m []byte) ([]byte, error) {
+	if err := eia3.ValidateLength(m, uint64(len(m))*8); err != nil {
+		return nil, err
+	}
+
+	integrity, err := algorithm.NewIntegrity(c.integrityAlg, c.integrityKey, count, bearer, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return integrity.Hash(m, uint32(len(m))*8), nil
+}
+
+func (c *Context) cipher(count uint32, bearer uint32, dir zuc.KeyDirection, m []byte) ([]byte, error) {
+	if err := eea3.ValidateLength(m, uint64(len(m))*8); err != nil {
+		return nil, err
+	}
+
+	cipher, err := algorithm.NewCipher(c.cipherAlg, c.cipherKey, count, bearer, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.Encrypt(m, uint32(len(m))*8), nil
+}
+
+// advance moves to the next COUNT, refusing to wrap around to a COUNT already used with these keys.
+func (b *bearerState) advance(dir zuc.KeyDirection) {
+	if b.count[dir] == 0xffffffff {
+		b.exhausted[dir] = true
+	} else {
+		b.count[dir] += 1
+	}
+}
+
+// Protect appends MAC-I to pdu and ciphers both with the next COUNT of bearer in direction dir.
+func (c *Context) Protect(bearer uint32, dir zuc.KeyDirection, pdu []byte) ([]byte, error) {
+	b, err := c.bearer(bearer)
+	if err != nil {
+		return nil, err
+	}
+
+	dir &= 1
+
+	b.Lock()
+	defer b.Unlock()
+
+	if b.exhausted[dir] {
+		return nil, ErrCountExhausted
+	}
+
+	count := b.count[dir]
+
+	mac, err := c.mac(count, bearer, dir, pdu)
+	if err != nil {
+		return nil, err
+	}
+
+	protected := make([]byte, 0, len(pdu)+MACSize)
+	protected = append(protected, pdu...)
+	protected = append(protected, mac...)
+
+	protected, err = c.cipher(count, bearer, dir, protected)
+	if err != nil {
+		return nil, err
+	}
+
+	b.advance(dir)
+
+	return protected, nil
+}
+
+// Unprotect deciphers pdu with the next COUNT of bearer in direction dir and verifies MAC-I.
+// COUNT only advances when verification succeeds.
+func (c *Context) Unprotect(bearer uint32, dir zuc.KeyDirection, pdu []byte) ([]byte, error) {
+	b, err := c.bearer(bearer)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pdu) < MACSize {
+		return nil, ErrPDUTooShort
+	}
+
+	dir &= 1
+
+	b.Lock()
+	defer b.Unlock()
+
+	if b.exhausted[dir] {
+		return nil, ErrCountExhausted
+	}
+
+	count := b.count[dir]
+
+	deciphered, err := c.cipher(count, bearer, dir, pdu)
+	if err != nil {
+		return nil, err
+	}
+
+	plain := deciphered[:len(deciphered)-MACSize]
+
+	if c.integrityAlg != EIA0 {
+		mac, err := c.mac(count, bearer, dir, plain)
+		if err != nil {
+			return nil, err
+		}
+
+		if subtle.ConstantTimeCompare(mac, deciphered[len(plain):]) != 1 {
+			return nil, ErrIntegrityFailed
+		}
+	}
+
+	b.advance(dir)
+
+	return plain, nil
+}
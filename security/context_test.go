@@ -0,0 +1,144 @@
+package security
+
+import (
+	"encoding/hex"
+	"fmt"
+	"github.com/frankurcrazy/zuc"
+	"github.com/frankurcrazy/zuc/eea3"
+	"github.com/frankurcrazy/zuc/eia3"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+)
+
+var (
+	ck, _ = hex.DecodeString("173d14ba5003731d7a60049470f00a29")
+	ik, _ = hex.DecodeString("c9e6cec4607c72db000aefa88385ab0a")
+)
+
+func TestNewContext(t *testing.T) {
+	_, err := NewContext(ck[:8], ik, EEA3, EIA3)
+	assert.Equal(t, ErrKeySize, err)
+
+	_, err = NewContext(ck, ik, 1, EIA3)
+	assert.Equal(t, ErrAlgorithm, err)
+
+	_, err = NewContext(ck, ik, EEA3, 2)
+	assert.Equal(t, ErrAlgorithm, err)
+}
+
+func TestProtect(t *testing.T) {
+	pdu, _ := hex.DecodeString("80006cf65340735552ab0c9752fa6f9025fe")
+
+	ue, _ := NewContext(ck, ik, EEA3, EIA3)
+	network, _ := NewContext(ck, ik, EEA3, EIA3)
+
+	assert.Nil(t, ue.SetCount(4, zuc.KEY_UPLINK, 0x66035492))
+	assert.Nil(t, network.SetCount(4, zuc.KEY_UPLINK, 0x66035492))
+
+	protected, err := ue.Protect(4, zuc.KEY_UPLINK, pdu)
+	assert.Nil(t, err)
+
+	mac := eia3.NewEIA3(ik, 0x66035492, 4, zuc.KEY_UPLINK).Hash(pdu, uint32(len(pdu)*8))
+	expected := eea3.NewEEA3(ck, 0x66035492, 4, zuc.KEY_UPLINK).Encrypt(append(append([]byte{}, pdu...), mac...), uint32(len(pdu)+4)*8)
+	assert.Equal(t, expected, protected, "Protected PDU mismatched!")
+
+	count, _ := ue.Count(4, zuc.KEY_UPLINK)
+	assert.Equal(t, uint32(0x66035493), count)
+
+	tampered := append([]byte{}, protected...)
+	tampered[2] ^= 0x80
+	_, err = network.Unprotect(4, zuc.KEY_UPLINK, tampered)
+	assert.Equal(t, ErrIntegrityFailed, err)
+
+	count, _ = network.Count(4, zuc.KEY_UPLINK)
+	assert.Equal(t, uint32(0x66035492), count, "COUNT should not advance on failure.")
+
+	plain, err := network.Unprotect(4, zuc.KEY_UPLINK, protected)
+	assert.Nil(t, err)
+	assert.Equal(t, pdu, plain)
+
+	_, err = network.Unprotect(4, zuc.KEY_UPLINK, protected)
+	assert.Equal(t, ErrIntegrityFailed, err, "Replayed PDU should fail with the next COUNT.")
+
+	count, _ = network.Count(4, zuc.KEY_DOWNLINK)
+	assert.Equal(t, uint32(0), count, "Downlink COUNT should be independent.")
+
+	_, err = network.Unprotect(4, zuc.KEY_UPLINK, protected[:3])
+	assert.Equal(t, ErrPDUTooShort, err)
+
+	_, err = ue.Protect(32, zuc.KEY_UPLINK, pdu)
+	assert.Equal(t, ErrBearer, err)
+}
+
+func TestNullAlgorithms(t *testing.T) {
+	pdu, _ := hex.DecodeString("80006cf65340")
+
+	c, _ := NewContext(ck, ik, EEA0, EIA0)
+	protected, err := c.Protect(1, zuc.KEY_DOWNLINK, pdu)
+	assert.Nil(t, err)
+	assert.Equal(t, append(append([]byte{}, pdu...), 0, 0, 0, 0), protected)
+
+	c, _ = NewContext(ck, ik, EEA0, EIA0)
+	plain, err := c.Unprotect(1, zuc.KEY_DOWNLINK, protected)
+	assert.Nil(t, err)
+	assert.Equal(t, pdu, plain)
+}
+
+func TestMessageTooLong(t *testing.T) {
+	c, _ := NewContext(ck, ik, EEA3, EIA3)
+
+	_, err := c.Protect(1, zuc.KEY_UPLINK, make([]byte, eia3.MaxLength/8+1))
+	assert.Equal(t, eia3.ErrMessageTooLong, err)
+
+	_, err = c.Protect(1, zuc.KEY_UPLINK, make([]byte, eia3.MaxLength/8))
+	assert.Equal(t, eea3.ErrLength, err)
+
+	_, err = c.Unprotect(1, zuc.KEY_UPLINK, make([]byte, eea3.MaxLength/8+1))
+	assert.Equal(t, eea3.ErrLength, err)
+
+	count, _ := c.Count(1, zuc.KEY_UPLINK)
+	assert.Equal(t, uint32(0), count)
+}
+
+func TestCountExhausted(t *testing.T) {
+	c, _ := NewContext(ck, ik, EEA3, EIA3)
+	c.SetCount(2, zuc.KEY_DOWNLINK, 0xffffffff)
+
+	_, err := c.Protect(2, zuc.KEY_DOWNLINK, []byte{0x01})
+	assert.Nil(t, err)
+
+	_, err = c.Protect(2, zuc.KEY_DOWNLINK, []byte{0x01})
+	assert.Equal(t, ErrCountExhausted, err)
+}
+
+func TestConcurrentBearers(t *testing.T) {
+	ue, _ := NewContext(ck, ik, EEA3, EIA3)
+	network, _ := NewContext(ck, ik, EEA3, EIA3)
+
+	wg := sync.WaitGroup{}
+	for bearer := uint32(0); bearer < MaxBearers; bearer += 1 {
+		wg.Add(1)
+		go func(bearer uint32) {
+			defer wg.Done()
+
+			for i := 0; i < 20; i += 1 {
+				pdu := []byte(fmt.Sprintf("bearer %d pdu %d", bearer, i))
+
+				protected, err := ue.Protect(bearer, zuc.KEY_UPLINK, pdu)
+				assert.Nil(t, err)
+
+				plain, err := network.Unprotect(bearer, zuc.KEY_UPLINK, protected)
+				assert.Nil(t, err)
+				assert.Equal(t, pdu, plain)
+			}
+		}(bearer)
+	}
+
+	wg.Wait()
+
+	for bearer := uint32(0); bearer < MaxBearers; bearer += 1 {
+		count, _ := network.Count(bearer, zuc.KEY_UPLINK)
+		assert.Equal(t, uint32(20), count)
+	}
+}
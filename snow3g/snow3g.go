@@ -0,0 +1,143 @@
+// Code adapted from ETSI / SAGE specification of the 3GPP Confidentiality and Integrity Algorithms UEA2 & UIA2.
+// Document 2: SNOW 3G Specification. Version 1.1 from 6th September 2006, annex 4.
+
+package snow3g
+
+import (
+	"encoding/binary"
+)
+
+// mulAlpha and divAlpha tabulate MULalpha and DIValpha, section 3.4.
+var (
+	mulAlpha [256]uint32
+	divAlpha [256]uint32
+)
+
+func mulx(v uint8, c uint8) uint8 {
+	if v&0x80 != 0 {
+		return (v << 1) ^ c
+	}
+
+	return v << 1
+}
+
+func mulxPow(v uint8, i int, c uint8) uint8 {
+	for ; i > 0; i -= 1 {
+		v = mulx(v, c)
+	}
+
+	return v
+}
+
+func init() {
+	for c := 0; c < 256; c += 1 {
+		v := uint8(c)
+		mulAlpha[c] = uint32(mulxPow(v, 23, 0xa9))<<24 | uint32(mulxPow(v, 245, 0xa9))<<16 |
+			uint32(mulxPow(v, 48, 0xa9))<<8 | uint32(mulxPow(v, 239, 0xa9))
+		divAlpha[c] = uint32(mulxPow(v, 16, 0xa9))<<24 | uint32(mulxPow(v, 39, 0xa9))<<16 |
+			uint32(mulxPow(v, 6, 0xa9))<<8 | uint32(mulxPow(v, 64, 0xa9))
+	}
+}
+
+// sbox applies the byte substitution box followed by the column mixing with reduction constant c,
+// giving S1 with SR and 0x1b and S2 with SQ and 0x69.
+func sbox(w uint32, box *[256]uint8, c uint8) uint32 {
+	w0 := box[w>>24]
+	w1 := box[(w>>16)&0xff]
+	w2 := box[(w>>8)&0xff]
+	w3 := box[w&0xff]
+
+	r0 := mulx(w0, c) ^ w1 ^ w2 ^ mulx(w3, c) ^ w3
+	r1 := mulx(w0, c) ^ w0 ^ mulx(w1, c) ^ w2 ^ w3
+	r2 := w0 ^ mulx(w1, c) ^ w1 ^ mulx(w2, c) ^ w3
+	r3 := w0 ^ w1 ^ mulx(w2, c) ^ w2 ^ mulx(w3, c)
+
+	return uint32(r0)<<24 | uint32(r1)<<16 | uint32(r2)<<8 | uint32(r3)
+}
+
+type SNOW3G struct {
+	s [16]uint32
+
+	r1, r2, r3 uint32
+
+	is_initialized bool
+}
+
+func (s *SNOW3G) clockLFSR(f uint32) {
+	v := (s.s[0] << 8) ^ mulAlpha[s.s[0]>>24] ^ s.s[2] ^ (s.s[11] >> 8) ^ divAlpha[s.s[11]&0xff] ^ f
+
+	copy(s.s[:15], s.s[1:])
+	s.s[15] = v
+}
+
+func (s *SNOW3G) clockFSM() uint32 {
+	f := (s.s[15] + s.r1) ^ s.r2
+	r := s.r2 + (s.r3 ^ s.s[5])
+
+	s.r3 = sbox(s.r2, &SQ, 0x69)
+	s.r2 = sbox(s.r1, &SR, 0x1b)
+	s.r1 = r
+
+	return f
+}
+
+// Initialization loads a 16-byte key and a 16-byte IV, read as k0||k1||k2||k3 and IV0||IV1||IV2||IV3
+// as in the test data of Document 3.
+func (s *SNOW3G) Initialization(k []uint8, iv []uint8) {
+	k0 := binary.BigEndian.Uint32(k[0:])
+	k1 := binary.BigEndian.Uint32(k[4:])
+	k2 := binary.BigEndian.Uint32(k[8:])
+	k3 := binary.BigEndian.Uint32(k[12:])
+
+	iv0 := binary.BigEndian.Uint32(iv[0:])
+	iv1 := binary.BigEndian.Uint32(iv[4:])
+	iv2 := binary.BigEndian.Uint32(iv[8:])
+	iv3 := binary.BigEndian.Uint32(iv[12:])
+
+	s.s = [16]uint32{
+		k0 ^ 0xffffffff, k1 ^ 0xffffffff, k2 ^ 0xffffffff, k3 ^ 0xffffffff,
+		k0, k1, k2, k3,
+		k0 ^ 0xffffffff, k1 ^ 0xffffffff ^ iv3, k2 ^ 0xffffffff ^ iv2, k3 ^ 0xffffffff,
+		k0 ^ iv1, k1, k2, k3 ^ iv0,
+	}
+
+	s.r1, s.r2, s.r3 = 0, 0, 0
+
+	for n := 32; n > 0; n -= 1 {
+		s.clockLFSR(s.clockFSM())
+	}
+
+	// The first keystream word is discarded.
+	s.clockFSM()
+	s.clockLFSR(0)
+
+	s.is_initialized = true
+}
+
+func (s *SNOW3G) GenerateKeystream(length uint32) []uint32 {
+	keys := []uint32{}
+
+	for i := uint32(0); i < length; i += 1 {
+		keys = append(keys, s.NextKey())
+	}
+
+	return keys
+}
+
+func (s *SNOW3G) NextKey() uint32 {
+	if !s.is_initialized {
+		panic("SNOW 3G not initialized.")
+	}
+
+	z := s.clockFSM() ^ s.s[0]
+	s.clockLFSR(0)
+
+	return z
+}
+
+func NewSNOW3G(k []uint8, iv []uint8) *SNOW3G {
+	s := &SNOW3G{}
+	s.Initialization(k, iv)
+
+	return s
+}
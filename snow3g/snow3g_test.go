@@ -0,0 +1,71 @@
+package snow3g
+
+import (
+	"encoding/hex"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestSNOW3G(t *testing.T) {
+	type TestSet struct {
+		Key string
+		IV  string
+		Z   map[int]string
+	}
+
+	testSets := map[string]TestSet{
+		"Test Set 1": TestSet{
+			Key: "2b d6 45 9f 82 c5 b3 00 95 2c 49 10 48 81 ff 48",
+			IV:  "ea 02 47 14 ad 5c 4d 84 df 1f 9b 25 1c 0b f4 5f",
+			Z:   map[int]string{0: "abee9704", 1: "7ac31373"},
+		},
+		"Test Set 2": TestSet{
+			Key: "8c e3 3e 2c c3 c0 b5 fc 1f 3d e8 a6 dc 66 b1 f3",
+			IV:  "d3 c5 d5 92 32 7f b1 1c de 55 19 88 ce b2 f9 b7",
+			Z:   map[int]string{0: "eff8a342", 1: "f751480f"},
+		},
+		"Test Set 3": TestSet{
+			Key: "40 35 c6 68 0a f8 c6 d1 a8 ff 86 67 b1 71 40 13",
+			IV:  "62 a5 40 98 1b a6 f9 b7 45 92 b0 e7 86 90 f7 1b",
+			Z:   map[int]string{0: "a8c874a9", 1: "7ae7c4f8"},
+		},
+		"Test Set 4": TestSet{
+			Key: "0d ed 72 63 10 9c f9 2e 33 52 25 5a 14 0e 0f 76",
+			IV:  "6b 68 07 9a 41 a7 c4 c9 1b ef d7 9f 7f dc c2 33",
+			Z:   map[int]string{0: "d712c05c", 1: "a937c2a6", 2499: "9c0db3aa"},
+		},
+	}
+
+	for n, ts := range testSets {
+		t.Run(n, func(t *testing.T) {
+			key, _ := hex.DecodeString(strings.Join(strings.Fields(ts.Key), ""))
+			iv, _ := hex.DecodeString(strings.Join(strings.Fields(ts.IV), ""))
+
+			length := 0
+			for i := range ts.Z {
+				if i+1 > length {
+					length = i + 1
+				}
+			}
+
+			z := NewSNOW3G(key, iv).GenerateKeystream(uint32(length))
+			for i, expected := range ts.Z {
+				assert.Equal(t, expected, fmt.Sprintf("%08x", z[i]), i)
+			}
+		})
+	}
+}
+
+func TestReinitialization(t *testing.T) {
+	key, _ := hex.DecodeString("2bd6459f82c5b300952c49104881ff48")
+	iv, _ := hex.DecodeString("ea024714ad5c4d84df1f9b251c0bf45f")
+
+	s := NewSNOW3G(key, key)
+	s.GenerateKeystream(10)
+	s.Initialization(key, iv)
+
+	assert.Equal(t, uint32(0xabee9704), s.NextKey())
+	assert.Panics(t, func() { (&SNOW3G{}).NextKey() })
+}
@@ -0,0 +1,68 @@
+// UEA1, the KASUMI based f8 confidentiality algorithm of 3GPP TS 35.201, Specification of the 3GPP
+// Confidentiality and Integrity Algorithms; Document 1: f8 and f9 Specification.
+
+package uea1
+
+import (
+	"encoding/binary"
+	"github.com/frankurcrazy/zuc"
+	"github.com/frankurcrazy/zuc/kasumi"
+)
+
+// KeyModifier is KM, XORed into every octet of CK for the initial encryption of the IV.
+const KeyModifier = uint8(0x55)
+
+type UEA1 struct {
+	kasumi *kasumi.KASUMI
+	a      uint64
+}
+
+// NewUEA1 panics if ck is not 16 bytes.
+func NewUEA1(ck []byte, count uint32, bearer uint32, direction zuc.KeyDirection) *UEA1 {
+	if len(ck) != kasumi.KeySize {
+		panic(kasumi.ErrKeySize)
+	}
+
+	modified := make([]byte, kasumi.KeySize)
+	for i := range modified {
+		modified[i] = ck[i] ^ KeyModifier
+	}
+
+	k, _ := kasumi.NewKASUMI(ck)
+	km, _ := kasumi.NewKASUMI(modified)
+
+	iv := uint64(count)<<32 | uint64(bearer&0x1f)<<27 | uint64(uint32(direction)&1)<<26
+
+	return &UEA1{kasumi: k, a: km.EncryptBlock(iv)}
+}
+
+func (e *UEA1) Encrypt(m []byte, blength uint32) []byte {
+	if uint64(blength) > uint64(len(m))*8 {
+		panic("uea1: buffer is shorter than bit length")
+	}
+
+	zeroBits := blength & 0x7
+	length := int((blength + 7) >> 3)
+	output := make([]byte, len(m))
+
+	ksb := uint64(0)
+	block := [kasumi.BlockSize]byte{}
+	for i, n := 0, uint64(0); i < length; i, n = i+kasumi.BlockSize, n+1 {
+		ksb = e.kasumi.EncryptBlock(e.a ^ n ^ ksb)
+		binary.BigEndian.PutUint64(block[:], ksb)
+
+		for j := 0; j < kasumi.BlockSize && i+j < length; j += 1 {
+			output[i+j] = m[i+j] ^ block[j]
+		}
+	}
+
+	if zeroBits > 0 {
+		output[length-1] = output[length-1] & (uint8(0xff) << (8 - zeroBits))
+	}
+
+	return output
+}
+
+func (e *UEA1) Decrypt(m []byte, blength uint32) []byte {
+	return e.Encrypt(m, blength)
+}
@@ -0,0 +1,92 @@
+package uea1
+
+import (
+	"encoding/hex"
+	"github.com/frankurcrazy/zuc"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+type TestSet struct {
+	Key        string
+	Count      uint32
+	Bearer     uint32
+	Direction  zuc.KeyDirection
+	BitLength  uint32
+	Plaintext  string
+	Ciphertext string
+}
+
+// 3GPP TS 35.203. f8 Test Set 2 is left out, the transcription available here is incomplete and does
+// not reproduce its published ciphertext.
+var testSets = map[string]TestSet{
+	"f8 Test Set 1": TestSet{
+		Key:       "2b d6 45 9f 82 c5 b3 00 95 2c 49 10 48 81 ff 48",
+		Count:     0x72a4f20f,
+		Bearer:    0x0c,
+		Direction: zuc.KEY_DOWNLINK,
+		BitLength: 798,
+		Plaintext: `7ec61272 743bf161 4726446a 6c38ced1 66f6ca76 eb543004 4286346c ef130f92
+			922b0345 0d3a9975 e5bd2ea0 eb55ad8e 1b199e3e c4316020 e9a1b285 e7627953
+			59b7bdfd 39bef4b2 484583d5 afe082ae e638bf5f d5a60619 3901a08f 4ab41aab
+			9b134880`,
+		Ciphertext: `d1e2de70 eef86c69 64fb542b c2d460aa bfaa10a4 a093262b 7d199e70 6fc2d489
+			15532969 10f3a973 012682e4 1c4e2b02 be2017b7 253bbf93 09de5819 cb42e819
+			56f4c99b c9765caf 53b1d0bb 8279826a dbbc5522 e915c120 a618a5a7 f5e89708
+			9339650c`,
+	},
+	"f8 Test Set 3": TestSet{
+		Key:        "5a cb 1d 64 4c 0d 51 20 4e a5 f1 45 10 10 d8 52",
+		Count:      0xfa556b26,
+		Bearer:     0x03,
+		Direction:  zuc.KEY_DOWNLINK,
+		BitLength:  120,
+		Plaintext:  "ad9c441f 890b38c4 57a49d42 1407e8",
+		Ciphertext: "9bc92ca8 03c67b28 a11a4bee 5a0c25",
+	},
+	"f8 Test Set 4": TestSet{
+		Key:        "d3 c5 d5 92 32 7f b1 1c 40 35 c6 68 0a f8 c6 d1",
+		Count:      0x398a59b4,
+		Bearer:     0x05,
+		Direction:  zuc.KEY_DOWNLINK,
+		BitLength:  253,
+		Plaintext:  "981ba682 4c1bfb1a b4854720 29b71d80 8ce33e2c c3c0b5fc 1f3de8a6 dc66b1f0",
+		Ciphertext: "5bb9431b b1e98bd1 1b93db7c 3d451365 59bb86a2 95aa204e cbebf6f7 a5101510",
+	},
+}
+
+func TestUEA1(t *testing.T) {
+	for n, ts := range testSets {
+		t.Run(n, func(t *testing.T) {
+			key, _ := hex.DecodeString(strings.Join(strings.Fields(ts.Key), ""))
+			plaintext, _ := hex.DecodeString(strings.Join(strings.Fields(ts.Plaintext), ""))
+			ciphertext, _ := hex.DecodeString(strings.Join(strings.Fields(ts.Ciphertext), ""))
+
+			assert.Equal(t, ciphertext, NewUEA1(key, ts.Count, ts.Bearer, ts.Direction).Encrypt(plaintext, ts.BitLength))
+			assert.Equal(t, plaintext[:len(plaintext)-1], NewUEA1(key, ts.Count, ts.Bearer, ts.Direction).Decrypt(ciphertext, ts.BitLength)[:len(plaintext)-1])
+		})
+	}
+
+	assert.Panics(t, func() { NewUEA1(make([]byte, 8), 0, 0, zuc.KEY_UPLINK) })
+}
+
+func TestEncryptLengths(t *testing.T) {
+	key, _ := hex.DecodeString("2bd6459f82c5b300952c49104881ff48")
+	m := make([]byte, 19)
+	full := NewUEA1(key, 1, 2, zuc.KEY_UPLINK).Encrypt(m, uint32(len(m))*8)
+
+	for blength := uint32(0); blength <= uint32(len(m))*8; blength += 1 {
+		out := NewUEA1(key, 1, 2, zuc.KEY_UPLINK).Encrypt(m, blength)
+		assert.Equal(t, len(m), len(out))
+
+		for i := uint32(0); i < uint32(len(m))*8; i += 1 {
+			bit := out[i/8] >> (7 - i%8) & 1
+			if i < blength {
+				assert.Equal(t, full[i/8]>>(7-i%8)&1, bit, blength)
+			} else {
+				assert.Equal(t, uint8(0), bit, blength)
+			}
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// UIA1, the KASUMI based f9 integrity algorithm of 3GPP TS 35.201, Specification of the 3GPP
+// Confidentiality and Integrity Algorithms; Document 1: f8 and f9 Specification.
+
+package uia1
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"github.com/frankurcrazy/zuc"
+	"github.com/frankurcrazy/zuc/kasumi"
+)
+
+// KeyModifier is KM, XORed into every octet of IK for the final encryption.
+const KeyModifier = uint8(0xaa)
+
+var (
+	ErrMACMismatch     = errors.New("uia1: MAC mismatch")
+	ErrMACLength       = errors.New("uia1: MAC must be 4 bytes")
+	ErrMessageTooShort = errors.New("uia1: message is shorter than bit length")
+)
+
+type UIA1 struct {
+	kasumi   *kasumi.KASUMI
+	modified *kasumi.KASUMI
+
+	count     uint32
+	fresh     uint32
+	direction zuc.KeyDirection
+}
+
+// NewUIA1 panics if ik is not 16 bytes.
+func NewUIA1(ik []byte, count uint32, fresh uint32, direction zuc.KeyDirection) *UIA1 {
+	if len(ik) != kasumi.KeySize {
+		panic(kasumi.ErrKeySize)
+	}
+
+	modified := make([]byte, kasumi.KeySize)
+	for i := range modified {
+		modified[i] = ik[i] ^ KeyModifier
+	}
+
+	k, _ := kasumi.NewKASUMI(ik)
+	km, _ := kasumi.NewKASUMI(modified)
+
+	return &UIA1{
+		kasumi:    k,
+		modified:  km,
+		count:     count,
+		fresh:     fresh,
+		direction: direction & 1,
+	}
+}
+
+// Hash returns MAC-I over the padded string COUNT || FRESH || MESSAGE || DIRECTION || 1 || 0...0.
+func (e *UIA1) Hash(m []byte, blen uint32) []byte {
+	if uint64(blen) > uint64(len(m))*8 {
+		panic("uia1: message is shorter than bit length")
+	}
+
+	// The message starts on the second block, the padding follows its last bit.
+	total := 64 + uint64(blen) + 2
+	ps := make([]byte, (total+63)/64*8)
+	binary.BigEndian.PutUint32(ps[0:], e.count)
+	binary.BigEndian.PutUint32(ps[4:], e.fresh)
+	copy(ps[8:], m[:(blen+7)/8])
+
+	if rem := blen % 8; rem != 0 {
+		ps[8+blen/8] &= 0xff << (8 - rem)
+	}
+
+	bit := 64 + uint64(blen)
+	if e.direction == zuc.KEY_DOWNLINK {
+		ps[bit/8] |= 0x80 >> (bit % 8)
+	}
+	bit += 1
+	ps[bit/8] |= 0x80 >> (bit % 8)
+
+	a, b := uint64(0), uint64(0)
+	for i := 0; i < len(ps); i += kasumi.BlockSize {
+		a = e.kasumi.EncryptBlock(a ^ binary.BigEndian.Uint64(ps[i:]))
+		b ^= a
+	}
+
+	b = e.modified.EncryptBlock(b)
+
+	mac := make([]byte, 4)
+	binary.BigEndian.PutUint32(mac, uint32(b>>32))
+
+	return mac
+}
+
+func (e *UIA1) Verify(m []byte, blen uint32, mac []byte) bool {
+	return e.VerifyMAC(m, blen, mac) == nil
+}
+
+// VerifyMAC checks mac against the MAC of the first blen bits of m in constant time.
+func (e *UIA1) VerifyMAC(m []byte, blen uint32, mac []byte) error {
+	if len(mac) != 4 {
+		return ErrMACLength
+	}
+
+	if uint64(blen) > uint64(len(m))*8 {
+		return ErrMessageTooShort
+	}
+
+	if subtle.ConstantTimeCompare(e.Hash(m, blen), mac) != 1 {
+		return ErrMACMismatch
+	}
+
+	return nil
+}
